@@ -0,0 +1,239 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// APIKeyHeader is the header clients send their API key in. "Authorization: Bearer <key>" is accepted too.
+const APIKeyHeader = "X-API-Key"
+
+// APIKey is a client key and its usage quotas per quota window. A zero quota means unlimited.
+type APIKey struct {
+	Key          string
+	Name         string
+	RequestQuota int64
+	ByteQuota    int64
+}
+
+// keyUsage is the usage of one key in the current quota window.
+type keyUsage struct {
+	windowStart time.Time
+	requests    int64
+	bytes       int64
+}
+
+// KeyStore authenticates requests by API key and enforces the per-key quotas.
+type KeyStore struct {
+	mu     sync.Mutex
+	keys   map[string]APIKey
+	usage  map[string]*keyUsage
+	window time.Duration
+	now    func() time.Time
+}
+
+// NewKeyStore returns a KeyStore for the given keys whose quotas reset every window.
+func NewKeyStore(keys []APIKey, window time.Duration) *KeyStore {
+	ks := &KeyStore{
+		keys:   make(map[string]APIKey, len(keys)),
+		usage:  make(map[string]*keyUsage, len(keys)),
+		window: window,
+		now:    time.Now,
+	}
+	for _, key := range keys {
+		if key.Name == "" {
+			key.Name = key.Key[:min(4, len(key.Key))] + "..."
+		}
+		ks.keys[key.Key] = key
+	}
+	return ks
+}
+
+// Middleware rejects requests without a known API key (401/403) or over their quota (429),
+// and attributes the request and its uploaded bytes to the key.
+func (ks *KeyStore) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 1st Step: authenticate the request
+		token := requestAPIKey(r)
+		if token == "" {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="matrix"`)
+			writeError(w, r, "missing API key: send it in the "+APIKeyHeader+" header", http.StatusUnauthorized)
+			return
+		}
+		key, ok := ks.keys[token]
+		if !ok {
+			writeError(w, r, "invalid API key", http.StatusForbidden)
+			return
+		}
+		if info := getRequestInfo(r); info != nil {
+			info.Client = key.Name
+			info.KeyID = apiKeyID(token)
+		}
+
+		// 2nd Step: check and count the quotas
+		retryAfter, err := ks.reserve(key, r.ContentLength)
+		if err != nil {
+			w.Header().Set("Retry-After", retryAfterSeconds(retryAfter))
+			writeError(w, r, err.Error(), http.StatusTooManyRequests)
+			return
+		}
+
+		// 3rd Step: serve the request and count the bytes actually read
+		body := &countingReader{ReadCloser: r.Body}
+		r.Body = body
+		next.ServeHTTP(w, r)
+		ks.addBytes(key, body.n)
+	})
+}
+
+// reserve counts one request against the key, or returns an error and the time until the
+// quota window resets if the key is over its request or byte quota.
+func (ks *KeyStore) reserve(key APIKey, contentLength int64) (time.Duration, error) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	usage := ks.currentUsage(key)
+	retryAfter := usage.windowStart.Add(ks.window).Sub(ks.now())
+
+	if key.RequestQuota > 0 && usage.requests >= key.RequestQuota {
+		return retryAfter, fmt.Errorf("request quota exceeded for %s: %d requests per %s", key.Name, key.RequestQuota, ks.window)
+	}
+	if key.ByteQuota > 0 && (usage.bytes >= key.ByteQuota || usage.bytes+max(contentLength, 0) > key.ByteQuota) {
+		return retryAfter, fmt.Errorf("byte quota exceeded for %s: %d bytes per %s, %d bytes used", key.Name, key.ByteQuota, ks.window, usage.bytes)
+	}
+
+	usage.requests++
+	return 0, nil
+}
+
+// addBytes counts the processed bytes against the key.
+func (ks *KeyStore) addBytes(key APIKey, n int64) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	ks.currentUsage(key).bytes += n
+}
+
+// currentUsage returns the usage of the key, starting a new window if the last one ended.
+// The caller must hold ks.mu.
+func (ks *KeyStore) currentUsage(key APIKey) *keyUsage {
+	now := ks.now()
+	usage, ok := ks.usage[key.Key]
+	if !ok || now.Sub(usage.windowStart) >= ks.window {
+		usage = &keyUsage{windowStart: now}
+		ks.usage[key.Key] = usage
+	}
+	return usage
+}
+
+// retryAfterSeconds formats a wait time as a Retry-After value, rounded up to whole seconds.
+func retryAfterSeconds(d time.Duration) string {
+	return strconv.Itoa(max(int(math.Ceil(d.Seconds())), 1))
+}
+
+// requestAPIKey returns the API key sent with the request, or an empty string.
+func requestAPIKey(r *http.Request) string {
+	if key := r.Header.Get(APIKeyHeader); key != "" {
+		return strings.TrimSpace(key)
+	}
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		return strings.TrimSpace(token)
+	}
+	return ""
+}
+
+// countingReader counts the bytes read from the request body.
+type countingReader struct {
+	io.ReadCloser
+	n int64
+}
+
+func (cr *countingReader) Read(p []byte) (int, error) {
+	n, err := cr.ReadCloser.Read(p)
+	cr.n += int64(n)
+	return n, err
+}
+
+// LoadAPIKeysFile reads API keys from a csv file with "key,name,request_quota,byte_quota" rows.
+// Name and quotas are optional and lines starting with # are ignored.
+func LoadAPIKeysFile(path string) ([]APIKey, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read API keys file: %v", err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var keys []APIKey
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse API keys file: %v", err)
+		}
+		// Report the line in the file, comment lines included
+		line, _ := reader.FieldPos(0)
+
+		if len(row) > 4 || strings.TrimSpace(row[0]) == "" {
+			return nil, fmt.Errorf("failed to parse API keys file: line %d must be \"key,name,request_quota,byte_quota\"", line)
+		}
+		key := APIKey{Key: strings.TrimSpace(row[0])}
+		if len(row) > 1 {
+			key.Name = strings.TrimSpace(row[1])
+		}
+		quotas := []*int64{&key.RequestQuota, &key.ByteQuota}
+		for j := 2; j < len(row); j++ {
+			if row[j] == "" {
+				continue
+			}
+			quota, err := strconv.ParseInt(strings.TrimSpace(row[j]), 10, 64)
+			if err != nil || quota < 0 {
+				return nil, fmt.Errorf("failed to parse API keys file: line %d column %d is not a valid quota", line, j+1)
+			}
+			*quotas[j-2] = quota
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+// ParseAPIKeys parses comma separated "key" or "key:name" entries without quotas. An entry
+// with an empty key, such as ":team", is an error rather than a key anyone could send.
+func ParseAPIKeys(value string) ([]APIKey, error) {
+	var keys []APIKey
+	for i, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		key, name, _ := strings.Cut(entry, ":")
+		if strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("invalid API_KEYS: entry %d %q has an empty key", i+1, entry)
+		}
+		keys = append(keys, APIKey{Key: strings.TrimSpace(key), Name: strings.TrimSpace(name)})
+	}
+	return keys, nil
+}
+
+// apiKeyID returns a stable identifier of the key that does not reveal it. Key names are only
+// display names and may repeat, so the per-key state is keyed on this identifier.
+func apiKeyID(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type AuthTestSuite struct {
+	suite.Suite
+}
+
+// Test for LoadAPIKeysFile
+func (s *AuthTestSuite) TestLoadAPIKeysFile() {
+	keys, err := LoadAPIKeysFile("testdata/api_keys.csv")
+	s.Require().NoError(err)
+	s.Equal([]APIKey{
+		{Key: "secret-analytics", Name: "analytics", RequestQuota: 2},
+		{Key: "secret-billing", Name: "billing", ByteQuota: 400},
+		{Key: "secret-unlimited"},
+	}, keys)
+
+	_, err = LoadAPIKeysFile("testdata/missing.csv")
+	s.ErrorContains(err, "failed to read API keys file")
+
+	_, err = LoadAPIKeysFile("testdata/valid_4_to_4.csv")
+	s.ErrorContains(err, "line 2 column 3 is not a valid quota")
+}
+
+// Test for ParseAPIKeys
+func (s *AuthTestSuite) TestParseAPIKeys() {
+	keys, err := ParseAPIKeys(" key-a:team-a, ,key-b")
+	s.NoError(err)
+	s.Equal([]APIKey{
+		{Key: "key-a", Name: "team-a"},
+		{Key: "key-b"},
+	}, keys)
+
+	keys, err = ParseAPIKeys("")
+	s.NoError(err)
+	s.Empty(keys)
+
+	_, err = ParseAPIKeys("key-a:team-a,:team-b")
+	s.EqualError(err, "invalid API_KEYS: entry 2 \":team-b\" has an empty key")
+}
+
+// Test for KeyStore middleware through the router
+func (s *AuthTestSuite) TestAuthentication() {
+	keys, err := LoadAPIKeysFile("testdata/api_keys.csv")
+	s.Require().NoError(err)
+
+	tests := []struct {
+		name                   string
+		apiKey                 string
+		bearer                 bool
+		repeat                 int
+		expectedStatusCode     int
+		expectedResponseSubstr string
+		expectRetryAfter       bool
+	}{
+		{
+			name:                   "missing api key",
+			expectedStatusCode:     401,
+			expectedResponseSubstr: "missing API key",
+		},
+		{
+			name:                   "unknown api key",
+			apiKey:                 "wrong",
+			expectedStatusCode:     403,
+			expectedResponseSubstr: "invalid API key",
+		},
+		{
+			name:                   "valid api key",
+			apiKey:                 "secret-unlimited",
+			expectedStatusCode:     200,
+			expectedResponseSubstr: "45",
+		},
+		{
+			name:                   "valid bearer token",
+			apiKey:                 "secret-unlimited",
+			bearer:                 true,
+			expectedStatusCode:     200,
+			expectedResponseSubstr: "45",
+		},
+		{
+			name:                   "request quota exceeded",
+			apiKey:                 "secret-analytics",
+			repeat:                 3,
+			expectedStatusCode:     429,
+			expectedResponseSubstr: "request quota exceeded for analytics: 2 requests per 1h0m0s",
+			expectRetryAfter:       true,
+		},
+		{
+			name:                   "byte quota exceeded",
+			apiKey:                 "secret-billing",
+			repeat:                 3,
+			expectedStatusCode:     429,
+			expectedResponseSubstr: "byte quota exceeded for billing",
+			expectRetryAfter:       true,
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			router := NewRouter(Config{APIKeys: keys, QuotaWindow: time.Hour}, discardLogger())
+
+			var resp *http.Response
+			for range max(tc.repeat, 1) {
+				req := createCSVRequest(s.T(), "/sum", "testdata/valid_3_to_3.csv")
+				if tc.bearer {
+					req.Header.Set("Authorization", "Bearer "+tc.apiKey)
+				} else if tc.apiKey != "" {
+					req.Header.Set(APIKeyHeader, tc.apiKey)
+				}
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				resp = w.Result()
+			}
+			body, _ := io.ReadAll(resp.Body)
+
+			s.Equal(tc.expectedStatusCode, resp.StatusCode)
+			s.Contains(string(body), tc.expectedResponseSubstr)
+			if tc.expectedStatusCode != 200 {
+				s.Contains(string(body), "request id: ")
+			}
+			if tc.expectRetryAfter {
+				s.Equal("3600", resp.Header.Get("Retry-After"))
+			}
+		})
+	}
+}
+
+// Test that /debug/vars is only served when enabled, and behind the API keys when they are set
+func (s *AuthTestSuite) TestDebugVars() {
+	keys := []APIKey{{Key: "secret", Name: "team"}}
+	tests := []struct {
+		name               string
+		cfg                Config
+		apiKey             string
+		expectedStatusCode int
+	}{
+		{name: "disabled by default", cfg: Config{}, expectedStatusCode: 404},
+		{name: "enabled without keys", cfg: Config{DebugVars: true}, expectedStatusCode: 200},
+		{name: "enabled with keys and no key", cfg: Config{DebugVars: true, APIKeys: keys}, expectedStatusCode: 401},
+		{name: "enabled with keys and a valid key", cfg: Config{DebugVars: true, APIKeys: keys}, apiKey: "secret", expectedStatusCode: 200},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			req := httptest.NewRequest("GET", "/debug/vars", nil)
+			if tc.apiKey != "" {
+				req.Header.Set(APIKeyHeader, tc.apiKey)
+			}
+			w := httptest.NewRecorder()
+			NewRouter(tc.cfg, discardLogger()).ServeHTTP(w, req)
+
+			resp := w.Result()
+			body, _ := io.ReadAll(resp.Body)
+			s.Equal(tc.expectedStatusCode, resp.StatusCode)
+			if tc.expectedStatusCode == 200 {
+				s.Contains(string(body), `"panics_total"`)
+			} else {
+				s.NotContains(string(body), "cmdline")
+			}
+		})
+	}
+}
+
+// Test that quotas are reset when the quota window ends
+func (s *AuthTestSuite) TestQuotaWindowReset() {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	ks := NewKeyStore([]APIKey{{Key: "k", Name: "team", RequestQuota: 1}}, time.Minute)
+	ks.now = func() time.Time { return now }
+	handler := ks.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	serve := func() int {
+		req := createCSVRequest(s.T(), "/sum", "testdata/valid_2_to_2.csv")
+		req.Header.Set(APIKeyHeader, "k")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	s.Equal(200, serve())
+	now = now.Add(30 * time.Second)
+	s.Equal(429, serve())
+	now = now.Add(30 * time.Second)
+	s.Equal(200, serve())
+}
+
+// Run all tests
+func TestAuthTestSuite(t *testing.T) {
+	suite.Run(t, new(AuthTestSuite))
+}
@@ -0,0 +1,265 @@
+// Package client calls the matrix service over HTTP.
+//
+// Every method uploads a CSV file with a square matrix of integers, the same way as
+//
+//	curl -F 'file=@matrix.csv' "localhost:8080/echo"
+//
+// and decodes the result into Go values. Failed requests return an *Error.
+package client
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Client calls the matrix service. Its fields may be changed before the first call.
+type Client struct {
+	// BaseURL of the service, such as "http://localhost:8080"
+	BaseURL string
+	// APIKey is sent in the X-API-Key header when not empty
+	APIKey string
+	// HTTPClient sends the requests, http.DefaultClient by default
+	HTTPClient *http.Client
+	// MaxRetries is how many times a rate limited or unavailable request is retried
+	MaxRetries int
+	// RetryBackoff is the wait before the first retry, doubled for every following one.
+	// A Retry-After header sent by the server takes precedence.
+	RetryBackoff time.Duration
+	// MaxRetryWait caps the wait before any retry. Requests are not retried when the
+	// server asks for a longer wait.
+	MaxRetryWait time.Duration
+	// Compress gzips the uploaded request bodies, which is faster for large matrices
+	Compress bool
+}
+
+// New returns a Client for the service at baseURL that retries up to 3 times.
+func New(baseURL string) *Client {
+	return &Client{
+		BaseURL:      strings.TrimSuffix(baseURL, "/"),
+		HTTPClient:   http.DefaultClient,
+		MaxRetries:   3,
+		RetryBackoff: 200 * time.Millisecond,
+		MaxRetryWait: 30 * time.Second,
+	}
+}
+
+// Echo returns the matrix in the CSV file.
+func (c *Client) Echo(ctx context.Context, csv io.Reader) ([][]int64, error) {
+	body, err := c.call(ctx, "/echo", csv)
+	if err != nil {
+		return nil, err
+	}
+	return parseMatrix(body)
+}
+
+// Transpose returns the matrix in the CSV file with the columns and rows inverted.
+func (c *Client) Transpose(ctx context.Context, csv io.Reader) ([][]int64, error) {
+	body, err := c.call(ctx, "/invert", csv)
+	if err != nil {
+		return nil, err
+	}
+	return parseMatrix(body)
+}
+
+// Flatten returns the values of the matrix in the CSV file row by row.
+func (c *Client) Flatten(ctx context.Context, csv io.Reader) ([]int64, error) {
+	body, err := c.call(ctx, "/flatten", csv)
+	if err != nil {
+		return nil, err
+	}
+	return parseRow(strings.TrimSpace(body))
+}
+
+// Sum returns the sum of the integers in the matrix in the CSV file.
+func (c *Client) Sum(ctx context.Context, csv io.Reader) (int64, error) {
+	body, err := c.call(ctx, "/sum", csv)
+	if err != nil {
+		return 0, err
+	}
+	sum, err := strconv.ParseInt(strings.TrimSpace(body), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to decode sum: %v", err)
+	}
+	return sum, nil
+}
+
+// Multiply returns the product of the integers in the matrix in the CSV file.
+func (c *Client) Multiply(ctx context.Context, csv io.Reader) (*big.Int, error) {
+	body, err := c.call(ctx, "/multiply", csv)
+	if err != nil {
+		return nil, err
+	}
+	product, ok := new(big.Int).SetString(strings.TrimSpace(body), 10)
+	if !ok {
+		return nil, fmt.Errorf("failed to decode product: %q is not an integer", strings.TrimSpace(body))
+	}
+	return product, nil
+}
+
+// call uploads the CSV file to the endpoint and returns the response body,
+// retrying rate limited and unavailable requests.
+func (c *Client) call(ctx context.Context, endpoint string, csv io.Reader) (string, error) {
+	// 1st Step: build the multipart body once so that it can be sent again on retries
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("file", "matrix.csv")
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(part, csv); err != nil {
+		return "", fmt.Errorf("failed to read csv: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		return "", err
+	}
+	if c.Compress {
+		compressed := &bytes.Buffer{}
+		gz := gzip.NewWriter(compressed)
+		gz.Write(body.Bytes())
+		if err := gz.Close(); err != nil {
+			return "", err
+		}
+		body = compressed
+	}
+
+	// 2nd Step: send the request until it succeeds or cannot be retried
+	for attempt := 0; ; attempt++ {
+		respBody, err := c.send(ctx, endpoint, body.Bytes(), writer.FormDataContentType())
+		if err == nil {
+			return respBody, nil
+		}
+
+		wait, retry := c.retryWait(err, attempt)
+		if !retry {
+			return "", err
+		}
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// send makes a single request and returns the body of a successful response, or an error.
+func (c *Client) send(ctx context.Context, endpoint string, body []byte, contentType string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.BaseURL+endpoint, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", contentType)
+	if c.Compress {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	req.Header.Set("Accept", "text/plain, application/problem+json")
+	if c.APIKey != "" {
+		req.Header.Set("X-API-Key", c.APIKey)
+	}
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", decodeError(resp, respBody)
+	}
+	return string(respBody), nil
+}
+
+// retryWait reports whether a failed request should be retried and how long to wait first.
+func (c *Client) retryWait(err error, attempt int) (time.Duration, bool) {
+	if attempt >= c.MaxRetries || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return 0, false
+	}
+
+	wait := c.RetryBackoff << attempt
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		if !apiErr.Temporary() {
+			return 0, false
+		}
+		if apiErr.RetryAfter > 0 {
+			wait = apiErr.RetryAfter
+		}
+		// Give up at once when the server asks for a longer wait, such as for an exhausted quota
+		if c.MaxRetryWait > 0 && apiErr.RetryAfter > c.MaxRetryWait {
+			return 0, false
+		}
+	}
+	if c.MaxRetryWait > 0 {
+		wait = min(wait, c.MaxRetryWait)
+	}
+	return wait, true
+}
+
+// decodeError builds an *Error from a failed response, structured or plain text.
+func decodeError(resp *http.Response, body []byte) *Error {
+	apiErr := &Error{
+		StatusCode: resp.StatusCode,
+		RequestID:  resp.Header.Get("X-Request-ID"),
+		Message:    strings.TrimSpace(string(body)),
+	}
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		apiErr.RetryAfter = time.Duration(seconds) * time.Second
+	}
+
+	var problem struct {
+		Detail    string `json:"detail"`
+		RequestID string `json:"request_id"`
+	}
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "application/problem+json") && json.Unmarshal(body, &problem) == nil {
+		apiErr.Message = problem.Detail
+		if problem.RequestID != "" {
+			apiErr.RequestID = problem.RequestID
+		}
+	}
+	return apiErr
+}
+
+// parseMatrix decodes rows of comma separated integers.
+func parseMatrix(body string) ([][]int64, error) {
+	var matrix [][]int64
+	for _, line := range strings.Split(strings.TrimSpace(body), "\n") {
+		row, err := parseRow(line)
+		if err != nil {
+			return nil, err
+		}
+		matrix = append(matrix, row)
+	}
+	return matrix, nil
+}
+
+// parseRow decodes a line of comma separated integers.
+func parseRow(line string) ([]int64, error) {
+	fields := strings.Split(line, ",")
+	row := make([]int64, len(fields))
+	for i, field := range fields {
+		num, err := strconv.ParseInt(field, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode matrix value %q: %v", field, err)
+		}
+		row[i] = num
+	}
+	return row, nil
+}
@@ -0,0 +1,146 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ClientTestSuite struct {
+	suite.Suite
+}
+
+// Helper function to start a server that fails with the given responses before answering "45"
+func (s *ClientTestSuite) startServer(failures []int, retryAfter string) (*httptest.Server, *int32) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		call := atomic.AddInt32(&calls, 1)
+		s.NoError(r.ParseMultipartForm(1 << 20))
+		s.Contains(r.MultipartForm.File, "file")
+
+		if int(call) <= len(failures) {
+			w.Header().Set("Retry-After", retryAfter)
+			w.Header().Set("X-Request-ID", "req-1")
+			http.Error(w, "try again later", failures[call-1])
+			return
+		}
+		w.Write([]byte("45\n"))
+	}))
+	s.T().Cleanup(server.Close)
+	return server, &calls
+}
+
+// Helper function to create a client that retries without waiting
+func (s *ClientTestSuite) newClient(baseURL string) *Client {
+	c := New(baseURL)
+	c.RetryBackoff = time.Millisecond
+	return c
+}
+
+// Test for the retries of Client
+func (s *ClientTestSuite) TestRetries() {
+	tests := []struct {
+		name          string
+		failures      []int
+		retryAfter    string
+		maxRetries    int
+		expectErr     error
+		expectedCalls int32
+	}{
+		{
+			name:          "success without retry",
+			maxRetries:    3,
+			expectedCalls: 1,
+		},
+		{
+			name:          "unavailable and rate limited requests are retried",
+			failures:      []int{503, 429},
+			maxRetries:    3,
+			expectedCalls: 3,
+		},
+		{
+			name:          "gives up after max retries",
+			failures:      []int{503, 503, 503},
+			maxRetries:    2,
+			expectErr:     ErrServer,
+			expectedCalls: 3,
+		},
+		{
+			name:          "invalid matrix is not retried",
+			failures:      []int{400},
+			maxRetries:    3,
+			expectErr:     ErrInvalidMatrix,
+			expectedCalls: 1,
+		},
+		{
+			name:          "retry after longer than max wait is not retried",
+			failures:      []int{429},
+			retryAfter:    "3600",
+			maxRetries:    3,
+			expectErr:     ErrRateLimited,
+			expectedCalls: 1,
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			server, calls := s.startServer(tc.failures, tc.retryAfter)
+			c := s.newClient(server.URL)
+			c.MaxRetries = tc.maxRetries
+
+			sum, err := c.Sum(context.Background(), strings.NewReader("1,2\n3,4"))
+			if tc.expectErr != nil {
+				s.ErrorIs(err, tc.expectErr)
+				var apiErr *Error
+				s.Require().ErrorAs(err, &apiErr)
+				s.Equal("try again later", apiErr.Message)
+				s.Equal("req-1", apiErr.RequestID)
+			} else {
+				s.NoError(err)
+				s.Equal(int64(45), sum)
+			}
+			s.Equal(tc.expectedCalls, atomic.LoadInt32(calls))
+		})
+	}
+}
+
+// Test that waiting for a retry stops when the context is done
+func (s *ClientTestSuite) TestContextCancel() {
+	server, calls := s.startServer([]int{503, 503}, "1")
+	c := New(server.URL)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := c.Sum(ctx, strings.NewReader("1,2\n3,4"))
+	s.ErrorIs(err, context.DeadlineExceeded)
+	s.Equal(int32(1), atomic.LoadInt32(calls))
+}
+
+// Test for decoding a structured error
+func (s *ClientTestSuite) TestProblemError() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.Contains(r.Header.Get("Accept"), "application/problem+json")
+		w.Header().Set("Content-Type", "application/problem+json")
+		w.WriteHeader(403)
+		w.Write([]byte(`{"title":"Forbidden","status":403,"detail":"invalid API key","request_id":"abc"}`))
+	}))
+	defer server.Close()
+
+	c := New(server.URL)
+	c.APIKey = "wrong"
+	_, err := c.Echo(context.Background(), strings.NewReader("1"))
+
+	s.ErrorIs(err, ErrForbidden)
+	s.EqualError(err, "matrix service: 403 Forbidden: invalid API key (request id: abc)")
+}
+
+// Run all tests
+func TestClientTestSuite(t *testing.T) {
+	suite.Run(t, new(ClientTestSuite))
+}
@@ -0,0 +1,64 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Errors matched by errors.Is against an *Error with the corresponding status code.
+var (
+	// ErrInvalidMatrix means the file is missing, is not valid CSV or is not a square matrix of integers
+	ErrInvalidMatrix = errors.New("invalid matrix")
+	// ErrUnauthorized means the API key is missing
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrForbidden means the API key is not valid
+	ErrForbidden = errors.New("forbidden")
+	// ErrRateLimited means the rate limit or the quota of the API key is exceeded
+	ErrRateLimited = errors.New("rate limited")
+	// ErrServer means the service failed to handle the request
+	ErrServer = errors.New("server error")
+)
+
+// Error is a failed response from the service.
+type Error struct {
+	StatusCode int
+	Message    string
+	RequestID  string
+	// RetryAfter is the wait the server asked for before retrying, if any
+	RetryAfter time.Duration
+}
+
+func (e *Error) Error() string {
+	if e.RequestID != "" {
+		return fmt.Sprintf("matrix service: %d %s: %s (request id: %s)", e.StatusCode, http.StatusText(e.StatusCode), e.Message, e.RequestID)
+	}
+	return fmt.Sprintf("matrix service: %d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+// Is matches the Err* sentinel errors by status code.
+func (e *Error) Is(target error) bool {
+	switch target {
+	case ErrInvalidMatrix:
+		return e.StatusCode == http.StatusBadRequest
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrServer:
+		return e.StatusCode >= 500
+	}
+	return false
+}
+
+// Temporary reports whether the request may succeed if retried.
+func (e *Error) Temporary() bool {
+	switch e.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
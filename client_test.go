@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"math/big"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"league_code_test/client"
+)
+
+type ClientTestSuite struct {
+	suite.Suite
+}
+
+// Helper function to start the real server and return a client for it
+func (s *ClientTestSuite) startServer(cfg Config) *client.Client {
+	server := httptest.NewServer(NewRouter(cfg, discardLogger()))
+	s.T().Cleanup(server.Close)
+	return client.New(server.URL)
+}
+
+// Helper function to open a test file
+func (s *ClientTestSuite) openFile(filePath string) *os.File {
+	file, err := os.Open(filePath)
+	s.Require().NoError(err)
+	s.T().Cleanup(func() { file.Close() })
+	return file
+}
+
+// Test for every client method against the real handlers
+func (s *ClientTestSuite) TestOperations() {
+	c := s.startServer(Config{})
+	ctx := context.Background()
+
+	echo, err := c.Echo(ctx, s.openFile("testdata/valid_4_to_4.csv"))
+	s.NoError(err)
+	s.Equal([][]int64{{1, 2, 3, 4}, {2, 2, -1, -10}, {3, 3, 5, -2}, {4, 3, 2, 1}}, echo)
+
+	transposed, err := c.Transpose(ctx, s.openFile("testdata/valid_3_to_3.csv"))
+	s.NoError(err)
+	s.Equal([][]int64{{1, 4, 7}, {2, 5, 8}, {3, 6, 9}}, transposed)
+
+	flattened, err := c.Flatten(ctx, s.openFile("testdata/valid_2_to_2.csv"))
+	s.NoError(err)
+	s.Equal([]int64{0, 1, 2, 3}, flattened)
+
+	sum, err := c.Sum(ctx, s.openFile("testdata/valid_4_to_4.csv"))
+	s.NoError(err)
+	s.Equal(int64(22), sum)
+
+	product, err := c.Multiply(ctx, strings.NewReader("99999,99999\n99999,99999"))
+	s.NoError(err)
+	s.Equal(new(big.Int).Exp(big.NewInt(99999), big.NewInt(4), nil), product)
+}
+
+// Test that compressed uploads and responses are decoded by the client
+func (s *ClientTestSuite) TestCompression() {
+	c := s.startServer(Config{})
+	c.Compress = true
+	ctx := context.Background()
+
+	transposed, err := c.Transpose(ctx, s.openFile("testdata/valid_3_to_3.csv"))
+	s.NoError(err)
+	s.Equal([][]int64{{1, 4, 7}, {2, 5, 8}, {3, 6, 9}}, transposed)
+
+	sum, err := c.Sum(ctx, s.openFile("testdata/valid_3_to_3.csv.gz"))
+	s.NoError(err)
+	s.Equal(int64(45), sum)
+}
+
+// Test that server errors are decoded into client errors
+func (s *ClientTestSuite) TestErrors() {
+	ctx := context.Background()
+
+	c := s.startServer(Config{})
+	_, err := c.Sum(ctx, s.openFile("testdata/more_rows_than_cols.csv"))
+	s.ErrorIs(err, client.ErrInvalidMatrix)
+	var apiErr *client.Error
+	s.Require().ErrorAs(err, &apiErr)
+	s.Equal(400, apiErr.StatusCode)
+	s.Equal("matrix is not square: 3 rows and 2 columns", apiErr.Message)
+	s.NotEmpty(apiErr.RequestID)
+
+	keys, err := ParseAPIKeys("secret:team")
+	s.Require().NoError(err)
+	c = s.startServer(Config{APIKeys: keys})
+	_, err = c.Sum(ctx, s.openFile("testdata/valid_2_to_2.csv"))
+	s.ErrorIs(err, client.ErrUnauthorized)
+
+	c.APIKey = "wrong"
+	_, err = c.Sum(ctx, s.openFile("testdata/valid_2_to_2.csv"))
+	s.ErrorIs(err, client.ErrForbidden)
+
+	c.APIKey = "secret"
+	sum, err := c.Sum(ctx, s.openFile("testdata/valid_2_to_2.csv"))
+	s.NoError(err)
+	s.Equal(int64(6), sum)
+
+	c = s.startServer(Config{RateLimits: map[costClass]RateLimit{costExpensive: {Rate: 0.01, Burst: 1}}})
+	c.MaxRetries = 0
+	_, err = c.Multiply(ctx, s.openFile("testdata/valid_2_to_2.csv"))
+	s.NoError(err)
+	_, err = c.Multiply(ctx, s.openFile("testdata/valid_2_to_2.csv"))
+	s.ErrorIs(err, client.ErrRateLimited)
+	s.Require().ErrorAs(err, &apiErr)
+	s.Equal(100*time.Second, apiErr.RetryAfter)
+}
+
+// Run all tests
+func TestClientTestSuite(t *testing.T) {
+	suite.Run(t, new(ClientTestSuite))
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// maxDecompressedBytes caps the size of a decompressed request body or uploaded file, so that
+// a small compressed upload cannot expand without bound.
+const maxDecompressedBytes = 1 << 30
+
+// gzipMagic starts every gzip stream.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// CompressionMiddleware decompresses request bodies sent with Content-Encoding: gzip and
+// compresses responses for clients that send Accept-Encoding: gzip. Other request encodings
+// such as zstd are rejected with 415.
+func CompressionMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 1st Step: decompress the request body
+		switch encoding := strings.ToLower(strings.TrimSpace(r.Header.Get("Content-Encoding"))); encoding {
+		case "", "identity":
+		case "gzip", "x-gzip":
+			gz, err := gzip.NewReader(r.Body)
+			if err != nil {
+				writeError(w, r, fmt.Sprintf("failed to decompress request body: %v", err), http.StatusBadRequest)
+				return
+			}
+			r.Body = http.MaxBytesReader(w, gz, maxDecompressedBytes)
+			r.Header.Del("Content-Encoding")
+			r.Header.Del("Content-Length")
+			r.ContentLength = -1
+		default:
+			w.Header().Set("Accept-Encoding", "gzip")
+			writeError(w, r, fmt.Sprintf("unsupported content encoding %q: use gzip", encoding), http.StatusUnsupportedMediaType)
+			return
+		}
+
+		// 2nd Step: compress the response
+		w.Header().Add("Vary", "Accept-Encoding")
+		if !acceptsGzip(r.Header.Get("Accept-Encoding")) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		gw := &gzipResponseWriter{ResponseWriter: w}
+		defer gw.Close()
+		next.ServeHTTP(gw, r)
+	})
+}
+
+// acceptsGzip reports whether the Accept-Encoding header allows a gzip response, such as
+// "gzip, deflate" but not "gzip;q=0".
+func acceptsGzip(acceptEncoding string) bool {
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		coding, params, _ := strings.Cut(part, ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if coding != "gzip" && coding != "x-gzip" && coding != "*" {
+			continue
+		}
+		name, value, _ := strings.Cut(strings.TrimSpace(params), "=")
+		if strings.TrimSpace(name) == "q" {
+			if q, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil && q == 0 {
+				continue
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// gzipResponseWriter compresses the response body. Compression starts with the first write,
+// so responses without a body, such as 204 and 304, are sent unchanged.
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	gz          *gzip.Writer
+	wroteHeader bool
+}
+
+func (gw *gzipResponseWriter) WriteHeader(code int) {
+	if gw.wroteHeader {
+		return
+	}
+	gw.wroteHeader = true
+
+	header := gw.ResponseWriter.Header()
+	if code != http.StatusNoContent && code != http.StatusNotModified && header.Get("Content-Encoding") == "" {
+		header.Set("Content-Encoding", "gzip")
+		header.Del("Content-Length")
+		gw.gz = gzip.NewWriter(gw.ResponseWriter)
+	}
+	gw.ResponseWriter.WriteHeader(code)
+}
+
+func (gw *gzipResponseWriter) Write(b []byte) (int, error) {
+	if !gw.wroteHeader {
+		// Detect the content type from the uncompressed body, as the ResponseWriter would
+		if gw.Header().Get("Content-Type") == "" {
+			gw.Header().Set("Content-Type", http.DetectContentType(b))
+		}
+		gw.WriteHeader(http.StatusOK)
+	}
+	if gw.gz == nil {
+		return gw.ResponseWriter.Write(b)
+	}
+	return gw.gz.Write(b)
+}
+
+// Close flushes the compressed body.
+func (gw *gzipResponseWriter) Close() error {
+	if gw.gz == nil {
+		return nil
+	}
+	return gw.gz.Close()
+}
+
+// Unwrap returns the underlying ResponseWriter for http.ResponseController.
+func (gw *gzipResponseWriter) Unwrap() http.ResponseWriter {
+	return gw.ResponseWriter
+}
+
+// decompressUpload returns a reader of the uploaded file that transparently decompresses
+// gzip files, such as matrix.csv.gz, detected from their content.
+func decompressUpload(file io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(file)
+	if magic, _ := br.Peek(len(gzipMagic)); !bytes.Equal(magic, gzipMagic) {
+		return br, nil
+	}
+	gz, err := gzip.NewReader(br)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress file: %v", err)
+	}
+	return &maxBytesReader{r: gz, remaining: maxDecompressedBytes}, nil
+}
+
+// maxBytesReader fails once more than the remaining bytes are read, instead of truncating the
+// input like io.LimitReader.
+type maxBytesReader struct {
+	r         io.Reader
+	remaining int64
+}
+
+func (mr *maxBytesReader) Read(p []byte) (int, error) {
+	if mr.remaining < 0 {
+		return 0, fmt.Errorf("decompressed file is larger than the limit of %d bytes", int64(maxDecompressedBytes))
+	}
+	if int64(len(p)) > mr.remaining+1 {
+		p = p[:mr.remaining+1]
+	}
+	n, err := mr.r.Read(p)
+	mr.remaining -= int64(n)
+	if mr.remaining < 0 {
+		return n, fmt.Errorf("decompressed file is larger than the limit of %d bytes", int64(maxDecompressedBytes))
+	}
+	return n, err
+}
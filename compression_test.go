@@ -0,0 +1,187 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type CompressionTestSuite struct {
+	suite.Suite
+}
+
+// newUploadRequest builds a multipart upload of the file, gzip compressing the whole body when compress is set.
+func (s *CompressionTestSuite) newUploadRequest(endpoint string, filePath string, compress bool) *http.Request {
+	req := createCSVRequest(s.T(), endpoint, filePath)
+	if compress {
+		body, err := io.ReadAll(req.Body)
+		s.Require().NoError(err)
+		compressed := &bytes.Buffer{}
+		gz := gzip.NewWriter(compressed)
+		gz.Write(body)
+		gz.Close()
+		req.Body = io.NopCloser(compressed)
+		req.ContentLength = int64(compressed.Len())
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	return req
+}
+
+// Test for compressed request bodies and uploads
+func (s *CompressionTestSuite) TestCompressedUploads() {
+	tests := []struct {
+		name                   string
+		endpoint               string
+		filePath               string
+		contentEncoding        string
+		compressBody           bool
+		expectedStatusCode     int
+		expectedResponseSubstr string
+	}{
+		{
+			name:                   "gzip request body",
+			endpoint:               "/sum",
+			filePath:               "testdata/valid_3_to_3.csv",
+			compressBody:           true,
+			expectedStatusCode:     200,
+			expectedResponseSubstr: "45\n",
+		},
+		{
+			name:                   "gzip csv upload",
+			endpoint:               "/echo",
+			filePath:               "testdata/valid_3_to_3.csv.gz",
+			expectedStatusCode:     200,
+			expectedResponseSubstr: "1,2,3\n4,5,6\n7,8,9\n",
+		},
+		{
+			name:                   "gzip matrix market upload",
+			endpoint:               "/sum",
+			filePath:               "testdata/sparse_coordinate.mtx.gz",
+			expectedStatusCode:     200,
+			expectedResponseSubstr: "9\n",
+		},
+		{
+			name:                   "invalid gzip request body",
+			endpoint:               "/sum",
+			filePath:               "testdata/valid_3_to_3.csv",
+			contentEncoding:        "gzip",
+			expectedStatusCode:     400,
+			expectedResponseSubstr: "failed to decompress request body: gzip: invalid header",
+		},
+		{
+			name:                   "unsupported zstd request body",
+			endpoint:               "/sum",
+			filePath:               "testdata/valid_3_to_3.csv",
+			contentEncoding:        "zstd",
+			expectedStatusCode:     415,
+			expectedResponseSubstr: "unsupported content encoding \"zstd\": use gzip",
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			req := s.newUploadRequest(tc.endpoint, tc.filePath, tc.compressBody)
+			if tc.contentEncoding != "" {
+				req.Header.Set("Content-Encoding", tc.contentEncoding)
+			}
+			w := httptest.NewRecorder()
+			NewRouter(Config{}, discardLogger()).ServeHTTP(w, req)
+
+			resp := w.Result()
+			body, _ := io.ReadAll(resp.Body)
+
+			s.Equal(tc.expectedStatusCode, resp.StatusCode)
+			s.Contains(string(body), tc.expectedResponseSubstr)
+			if tc.expectedStatusCode == 415 {
+				s.Equal("gzip", resp.Header.Get("Accept-Encoding"))
+			}
+		})
+	}
+}
+
+// Test for compressed responses
+func (s *CompressionTestSuite) TestCompressedResponses() {
+	tests := []struct {
+		name                    string
+		endpoint                string
+		acceptEncoding          string
+		expectedStatusCode      int
+		expectedContentEncoding string
+		expectedResponseSubstr  string
+	}{
+		{
+			name:                    "gzip accepted",
+			endpoint:                "/echo",
+			acceptEncoding:          "gzip, deflate, br",
+			expectedStatusCode:      200,
+			expectedContentEncoding: "gzip",
+			expectedResponseSubstr:  "1,2,3\n4,5,6\n7,8,9\n",
+		},
+		{
+			name:                    "errors are compressed too",
+			endpoint:                "/echo?format=xml",
+			acceptEncoding:          "gzip",
+			expectedStatusCode:      400,
+			expectedContentEncoding: "gzip",
+			expectedResponseSubstr:  "invalid format \"xml\"",
+		},
+		{
+			name:                   "gzip refused with q=0",
+			endpoint:               "/echo",
+			acceptEncoding:         "gzip;q=0, identity",
+			expectedStatusCode:     200,
+			expectedResponseSubstr: "1,2,3\n4,5,6\n7,8,9\n",
+		},
+		{
+			name:                   "only zstd accepted",
+			endpoint:               "/echo",
+			acceptEncoding:         "zstd",
+			expectedStatusCode:     200,
+			expectedResponseSubstr: "1,2,3\n4,5,6\n7,8,9\n",
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			req := s.newUploadRequest(tc.endpoint, "testdata/valid_3_to_3.csv", false)
+			req.Header.Set("Accept-Encoding", tc.acceptEncoding)
+			w := httptest.NewRecorder()
+			NewRouter(Config{}, discardLogger()).ServeHTTP(w, req)
+
+			resp := w.Result()
+			s.Equal(tc.expectedStatusCode, resp.StatusCode)
+			s.Equal(tc.expectedContentEncoding, resp.Header.Get("Content-Encoding"))
+			s.Contains(resp.Header.Values("Vary"), "Accept-Encoding")
+
+			var reader io.Reader = resp.Body
+			if tc.expectedContentEncoding == "gzip" {
+				gz, err := gzip.NewReader(resp.Body)
+				s.Require().NoError(err)
+				reader = gz
+			}
+			body, err := io.ReadAll(reader)
+			s.Require().NoError(err)
+			s.Contains(string(body), tc.expectedResponseSubstr)
+		})
+	}
+}
+
+// Test for acceptsGzip
+func (s *CompressionTestSuite) TestAcceptsGzip() {
+	s.True(acceptsGzip("gzip"))
+	s.True(acceptsGzip("deflate, GZIP;q=0.5"))
+	s.True(acceptsGzip("*"))
+	s.False(acceptsGzip(""))
+	s.False(acceptsGzip("gzip;q=0"))
+	s.False(acceptsGzip("zstd, br"))
+}
+
+// Run all tests
+func TestCompressionTestSuite(t *testing.T) {
+	suite.Run(t, new(CompressionTestSuite))
+}
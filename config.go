@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config holds the server settings, read from environment variables by LoadConfig.
+type Config struct {
+	// Port is the address the server listens on, such as ":8080"
+	Port string
+	// APIKeys enables API key authentication when not empty
+	APIKeys []APIKey
+	// QuotaWindow is how often the per-key quotas are reset
+	QuotaWindow time.Duration
+	// RateLimits is the per client budget of every cost class
+	RateLimits map[costClass]RateLimit
+	// CORSOrigins lists the browser origins allowed to call the API, "*" allows all
+	CORSOrigins []string
+	// DebugVars serves the expvar metrics at /debug/vars, which include the command line and
+	// memory statistics of the process
+	DebugVars bool
+}
+
+// LoadConfig reads the server settings from the environment:
+//   - PORT: listen port, defaults to 8080
+//   - API_KEYS_FILE: csv file with "key,name,request_quota,byte_quota" rows
+//   - API_KEYS: comma separated "key" or "key:name" entries without quotas
+//   - QUOTA_WINDOW: quota reset period such as "1h", defaults to 24h
+//   - RATE_LIMIT_CHEAP, RATE_LIMIT_EXPENSIVE: per client "rate/burst" budget such as "10/20",
+//     in requests per second, disabled by default
+//   - CORS_ALLOWED_ORIGINS: comma separated origins allowed to call the API from a browser, or "*"
+//   - DEBUG_VARS: "true" to serve the expvar metrics at /debug/vars, disabled by default
+func LoadConfig() (Config, error) {
+	cfg := Config{
+		Port:        ":8080",
+		QuotaWindow: 24 * time.Hour,
+	}
+
+	if port := os.Getenv("PORT"); port != "" {
+		cfg.Port = ":" + strings.TrimPrefix(port, ":")
+	}
+
+	if path := os.Getenv("API_KEYS_FILE"); path != "" {
+		keys, err := LoadAPIKeysFile(path)
+		if err != nil {
+			return cfg, err
+		}
+		cfg.APIKeys = append(cfg.APIKeys, keys...)
+	}
+	keys, err := ParseAPIKeys(os.Getenv("API_KEYS"))
+	if err != nil {
+		return cfg, err
+	}
+	cfg.APIKeys = append(cfg.APIKeys, keys...)
+
+	if window := os.Getenv("QUOTA_WINDOW"); window != "" {
+		d, err := time.ParseDuration(window)
+		if err != nil || d <= 0 {
+			return cfg, fmt.Errorf("invalid QUOTA_WINDOW %q: must be a positive duration", window)
+		}
+		cfg.QuotaWindow = d
+	}
+
+	for _, origin := range strings.Split(os.Getenv("CORS_ALLOWED_ORIGINS"), ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			cfg.CORSOrigins = append(cfg.CORSOrigins, origin)
+		}
+	}
+
+	if value := os.Getenv("DEBUG_VARS"); value != "" {
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return cfg, fmt.Errorf("invalid DEBUG_VARS %q: use true or false", value)
+		}
+		cfg.DebugVars = enabled
+	}
+
+	cfg.RateLimits = make(map[costClass]RateLimit)
+	for class, name := range map[costClass]string{costCheap: "RATE_LIMIT_CHEAP", costExpensive: "RATE_LIMIT_EXPENSIVE"} {
+		value := os.Getenv(name)
+		if value == "" {
+			continue
+		}
+		limit, err := ParseRateLimit(value)
+		if err != nil {
+			return cfg, fmt.Errorf("invalid %s %q: %v", name, value, err)
+		}
+		cfg.RateLimits[class] = limit
+	}
+
+	return cfg, nil
+}
+
+// ParseRateLimit parses a "rate/burst" budget such as "0.5/5". The burst defaults to the rate rounded up.
+func ParseRateLimit(value string) (RateLimit, error) {
+	rateValue, burstValue, hasBurst := strings.Cut(value, "/")
+	rate, err := strconv.ParseFloat(strings.TrimSpace(rateValue), 64)
+	if err != nil || math.IsNaN(rate) || math.IsInf(rate, 0) || rate <= 0 {
+		return RateLimit{}, fmt.Errorf("rate must be a positive number of requests per second")
+	}
+
+	limit := RateLimit{Rate: rate, Burst: int(math.Ceil(rate))}
+	if hasBurst {
+		burst, err := strconv.Atoi(strings.TrimSpace(burstValue))
+		if err != nil || burst < 1 {
+			return RateLimit{}, fmt.Errorf("burst must be a positive integer")
+		}
+		limit.Burst = burst
+	}
+	return limit, nil
+}
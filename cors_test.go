@@ -0,0 +1,85 @@
+package main
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type CORSTestSuite struct {
+	suite.Suite
+}
+
+// Test for CORSMiddleware through the router
+func (s *CORSTestSuite) TestCORSMiddleware() {
+	tests := []struct {
+		name                string
+		allowedOrigins      []string
+		method              string
+		origin              string
+		preflight           bool
+		expectedStatusCode  int
+		expectedAllowOrigin string
+	}{
+		{
+			name:                "preflight from allowed origin",
+			allowedOrigins:      []string{"https://app.example.com"},
+			method:              "OPTIONS",
+			origin:              "https://app.example.com",
+			preflight:           true,
+			expectedStatusCode:  204,
+			expectedAllowOrigin: "https://app.example.com",
+		},
+		{
+			name:                "preflight from other origin is not allowed",
+			allowedOrigins:      []string{"https://app.example.com"},
+			method:              "OPTIONS",
+			origin:              "https://evil.example.com",
+			preflight:           true,
+			expectedStatusCode:  405,
+			expectedAllowOrigin: "",
+		},
+		{
+			name:                "wildcard allows any origin",
+			allowedOrigins:      []string{"*"},
+			method:              "POST",
+			origin:              "https://evil.example.com",
+			expectedStatusCode:  400,
+			expectedAllowOrigin: "https://evil.example.com",
+		},
+		{
+			name:                "cors disabled by default",
+			method:              "OPTIONS",
+			origin:              "https://app.example.com",
+			preflight:           true,
+			expectedStatusCode:  405,
+			expectedAllowOrigin: "",
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			router := NewRouter(Config{CORSOrigins: tc.allowedOrigins}, discardLogger())
+			req := httptest.NewRequest(tc.method, "/sum", nil)
+			req.Header.Set("Origin", tc.origin)
+			if tc.preflight {
+				req.Header.Set("Access-Control-Request-Method", "POST")
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			resp := w.Result()
+			s.Equal(tc.expectedStatusCode, resp.StatusCode)
+			s.Equal(tc.expectedAllowOrigin, resp.Header.Get("Access-Control-Allow-Origin"))
+			if tc.preflight && tc.expectedAllowOrigin != "" {
+				s.Contains(resp.Header.Get("Access-Control-Allow-Headers"), "X-API-Key")
+			}
+		})
+	}
+}
+
+// Run all tests
+func TestCORSTestSuite(t *testing.T) {
+	suite.Run(t, new(CORSTestSuite))
+}
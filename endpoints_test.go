@@ -2,33 +2,42 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"io"
+	"math"
+	"mime"
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
+
+	"league_code_test/matrix"
 )
 
 type EndpointTestSuite struct {
 	suite.Suite
 }
 
-// Helper function to create request with CSV file
-func (s *EndpointTestSuite) createCSVRequest(endpoint string, filePath string) *http.Request {
+// createCSVRequest returns a multipart upload of the file to the endpoint, shared by the suites
+// that call the router.
+func createCSVRequest(t *testing.T, endpoint string, filePath string) *http.Request {
 	fileBytes, err := os.ReadFile(filePath)
-	s.Require().NoError(err)
+	require.NoError(t, err)
 
 	body := &bytes.Buffer{}
 	writer := multipart.NewWriter(body)
 
-	part, err := writer.CreateFormFile("file", "matrix.csv")
-	s.Require().NoError(err)
+	part, err := writer.CreateFormFile("file", filepath.Base(filePath))
+	require.NoError(t, err)
 
 	_, err = part.Write(fileBytes)
-	s.Require().NoError(err)
+	require.NoError(t, err)
 
 	writer.Close()
 
@@ -109,7 +118,7 @@ func (s *EndpointTestSuite) TestEchoEndpoint() {
 
 	for _, tc := range tests {
 		s.Run(tc.name, func() {
-			req := s.createCSVRequest("/echo", tc.filePath)
+			req := createCSVRequest(s.T(), "/echo", tc.filePath)
 			w := httptest.NewRecorder()
 			EchoHandler(w, req)
 
@@ -194,7 +203,7 @@ func (s *EndpointTestSuite) TestInvertEndpoint() {
 
 	for _, tc := range tests {
 		s.Run(tc.name, func() {
-			req := s.createCSVRequest("/invert", tc.filePath)
+			req := createCSVRequest(s.T(), "/invert", tc.filePath)
 			w := httptest.NewRecorder()
 			InvertHandler(w, req)
 
@@ -279,7 +288,7 @@ func (s *EndpointTestSuite) TestFlattenEndpoint() {
 
 	for _, tc := range tests {
 		s.Run(tc.name, func() {
-			req := s.createCSVRequest("/flatten", tc.filePath)
+			req := createCSVRequest(s.T(), "/flatten", tc.filePath)
 			w := httptest.NewRecorder()
 			FlattenHandler(w, req)
 
@@ -365,7 +374,7 @@ func (s *EndpointTestSuite) TestSumEndpoint() {
 
 	for _, tc := range tests {
 		s.Run(tc.name, func() {
-			req := s.createCSVRequest("/sum", tc.filePath)
+			req := createCSVRequest(s.T(), "/sum", tc.filePath)
 			w := httptest.NewRecorder()
 			SumHandler(w, req)
 
@@ -450,7 +459,7 @@ func (s *EndpointTestSuite) TestMultiplyEndpoint() {
 
 	for _, tc := range tests {
 		s.Run(tc.name, func() {
-			req := s.createCSVRequest("/multiply", tc.filePath)
+			req := createCSVRequest(s.T(), "/multiply", tc.filePath)
 			w := httptest.NewRecorder()
 			MultiplyHandler(w, req)
 
@@ -463,6 +472,993 @@ func (s *EndpointTestSuite) TestMultiplyEndpoint() {
 	}
 }
 
+// Test for the delimiter query parameter
+func (s *EndpointTestSuite) TestDelimiters() {
+	tests := []struct {
+		name                   string
+		query                  string
+		filePath               string
+		expectedStatusCode     int
+		expectedResponseSubstr string
+	}{
+		{
+			name:                   "semicolon file is auto detected",
+			filePath:               "testdata/valid_semicolon.csv",
+			expectedStatusCode:     200,
+			expectedResponseSubstr: "1,-2,3\n4,5,6\n7,8,9\n",
+		},
+		{
+			name:                   "tab file is auto detected",
+			filePath:               "testdata/valid_tab.tsv",
+			expectedStatusCode:     200,
+			expectedResponseSubstr: "1,2\n3,4\n",
+		},
+		{
+			name:                   "explicit semicolon delimiter",
+			query:                  "?delimiter=semicolon",
+			filePath:               "testdata/valid_semicolon.csv",
+			expectedStatusCode:     200,
+			expectedResponseSubstr: "1,-2,3\n4,5,6\n7,8,9\n",
+		},
+		{
+			name:                   "decimal comma in semicolon file",
+			filePath:               "testdata/decimal_comma.csv",
+			expectedStatusCode:     400,
+			expectedResponseSubstr: "matrix value at row 2, column 2 is not an integer: \"5,5\" is a decimal number",
+		},
+		{
+			name:                   "explicit delimiter that does not match the file",
+			query:                  "?delimiter=tab",
+			filePath:               "testdata/valid_3_to_3.csv",
+			expectedStatusCode:     400,
+			expectedResponseSubstr: "matrix has a header row or non-integer value at row 1, column 1",
+		},
+		{
+			name:                   "invalid delimiter",
+			query:                  "?delimiter=colon",
+			filePath:               "testdata/valid_3_to_3.csv",
+			expectedStatusCode:     400,
+			expectedResponseSubstr: "invalid delimiter \"colon\"",
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			req := createCSVRequest(s.T(), "/echo"+tc.query, tc.filePath)
+			w := httptest.NewRecorder()
+			EchoHandler(w, req)
+
+			resp := w.Result()
+			body, _ := io.ReadAll(resp.Body)
+
+			s.Equal(tc.expectedStatusCode, resp.StatusCode)
+			s.Contains(string(body), tc.expectedResponseSubstr)
+		})
+	}
+}
+
+// Test for Matrix Market input and output
+func (s *EndpointTestSuite) TestMatrixMarket() {
+	tests := []struct {
+		name                   string
+		endpoint               string
+		filePath               string
+		accept                 string
+		expectedStatusCode     int
+		expectedContentType    string
+		expectedResponseSubstr string
+	}{
+		{
+			name:                   "sparse coordinate upload echoed as csv",
+			endpoint:               "/echo",
+			filePath:               "testdata/sparse_coordinate.mtx",
+			expectedStatusCode:     200,
+			expectedResponseSubstr: "5,0,0,0\n0,0,0,7\n0,0,0,0\n0,-3,0,0\n",
+		},
+		{
+			name:                   "sparse coordinate upload inverted as coordinate",
+			endpoint:               "/invert?format=mtx",
+			filePath:               "testdata/sparse_coordinate.mtx",
+			expectedStatusCode:     200,
+			expectedContentType:    "application/x-matrix-market",
+			expectedResponseSubstr: "%%MatrixMarket matrix coordinate integer general\n4 4 3\n1 1 5\n2 4 -3\n4 2 7\n",
+		},
+		{
+			name:                   "csv upload echoed as array with accept header",
+			endpoint:               "/echo",
+			filePath:               "testdata/valid_2_to_2.csv",
+			accept:                 "application/x-matrix-market",
+			expectedStatusCode:     200,
+			expectedContentType:    "application/x-matrix-market",
+			expectedResponseSubstr: "%%MatrixMarket matrix array integer general\n2 2\n0\n2\n1\n3\n",
+		},
+		{
+			name:                   "csv upload echoed as coordinate",
+			endpoint:               "/echo?format=mtx-coordinate",
+			filePath:               "testdata/valid_2_to_2.csv",
+			expectedStatusCode:     200,
+			expectedResponseSubstr: "2 2 3\n1 2 1\n2 1 2\n2 2 3\n",
+		},
+		{
+			name:                   "symmetric array sum",
+			endpoint:               "/sum",
+			filePath:               "testdata/symmetric_array.mtx",
+			expectedStatusCode:     200,
+			expectedResponseSubstr: "31\n",
+		},
+		{
+			name:                   "sparse product is zero",
+			endpoint:               "/multiply",
+			filePath:               "testdata/sparse_coordinate.mtx",
+			expectedStatusCode:     200,
+			expectedResponseSubstr: "0",
+		},
+		{
+			name:                   "sparse flatten",
+			endpoint:               "/flatten",
+			filePath:               "testdata/sparse_coordinate.mtx",
+			expectedStatusCode:     200,
+			expectedResponseSubstr: "5,0,0,0,0,0,0,7,0,0,0,0,0,-3,0,0\n",
+		},
+		{
+			name:                   "not square matrix market",
+			endpoint:               "/echo",
+			filePath:               "testdata/not_square.mtx",
+			expectedStatusCode:     400,
+			expectedResponseSubstr: "matrix is not square: 3 rows and 2 columns",
+		},
+		{
+			name:                   "invalid output format",
+			endpoint:               "/echo?format=xml",
+			filePath:               "testdata/valid_2_to_2.csv",
+			expectedStatusCode:     400,
+			expectedResponseSubstr: "invalid format \"xml\"",
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			req := createCSVRequest(s.T(), tc.endpoint, tc.filePath)
+			if tc.accept != "" {
+				req.Header.Set("Accept", tc.accept)
+			}
+			w := httptest.NewRecorder()
+			NewRouter(Config{}, discardLogger()).ServeHTTP(w, req)
+
+			resp := w.Result()
+			body, _ := io.ReadAll(resp.Body)
+
+			s.Equal(tc.expectedStatusCode, resp.StatusCode)
+			s.Contains(string(body), tc.expectedResponseSubstr)
+			if tc.expectedContentType != "" {
+				s.Equal(tc.expectedContentType, resp.Header.Get("Content-Type"))
+			}
+		})
+	}
+}
+
+// Test for the lenient query parameter
+func (s *EndpointTestSuite) TestLenient() {
+	tests := []struct {
+		name                   string
+		query                  string
+		expectedStatusCode     int
+		expectedNormalizations string
+		expectedResponseSubstr string
+	}{
+		{
+			name:                   "excel export is rejected in strict mode",
+			expectedStatusCode:     400,
+			expectedResponseSubstr: "matrix has a header row or non-integer value at row 1, column 1",
+		},
+		{
+			name:                   "excel export is normalized in lenient mode",
+			query:                  "?lenient=true",
+			expectedStatusCode:     200,
+			expectedNormalizations: "bom, line-endings, comments, blank-lines, whitespace",
+			expectedResponseSubstr: "1,2,3\n4,5,6\n7,8,9\n",
+		},
+		{
+			name:                   "invalid lenient value",
+			query:                  "?lenient=maybe",
+			expectedStatusCode:     400,
+			expectedResponseSubstr: "invalid lenient \"maybe\": use true or false",
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			req := createCSVRequest(s.T(), "/echo"+tc.query, "testdata/excel_export.csv")
+			w := httptest.NewRecorder()
+			EchoHandler(w, req)
+
+			resp := w.Result()
+			body, _ := io.ReadAll(resp.Body)
+
+			s.Equal(tc.expectedStatusCode, resp.StatusCode)
+			s.Equal(tc.expectedNormalizations, resp.Header.Get(NormalizationsHeader))
+			s.Contains(string(body), tc.expectedResponseSubstr)
+		})
+	}
+}
+
+// Test for the header and labels query parameters and JSON output
+func (s *EndpointTestSuite) TestLabels() {
+	tests := []struct {
+		name                   string
+		endpoint               string
+		filePath               string
+		accept                 string
+		expectedStatusCode     int
+		expectedContentType    string
+		expectedResponseSubstr string
+	}{
+		{
+			name:                   "header row is echoed",
+			endpoint:               "/echo?header=true",
+			filePath:               "testdata/with_header.csv",
+			expectedStatusCode:     200,
+			expectedResponseSubstr: "a,b,c\n1,2,3\n4,5,6\n7,8,9\n",
+		},
+		{
+			name:                   "header row becomes the label column when inverted",
+			endpoint:               "/invert?header=true",
+			filePath:               "testdata/with_header.csv",
+			expectedStatusCode:     200,
+			expectedResponseSubstr: "a,1,4,7\nb,2,5,8\nc,3,6,9\n",
+		},
+		{
+			name:                   "labels are inverted",
+			endpoint:               "/invert?header=true&labels=true",
+			filePath:               "testdata/labeled.csv",
+			expectedStatusCode:     200,
+			expectedResponseSubstr: ",x,y,z\na,1,4,7\nb,2,5,8\nc,3,6,9\n",
+		},
+		{
+			name:                   "labels in JSON output",
+			endpoint:               "/echo?header=true&labels=true",
+			filePath:               "testdata/labeled.csv",
+			accept:                 "application/json",
+			expectedStatusCode:     200,
+			expectedContentType:    "application/json",
+			expectedResponseSubstr: `{"values":[[1,2,3],[4,5,6],[7,8,9]],"row_labels":["x","y","z"],"column_labels":["a","b","c"]}`,
+		},
+		{
+			name:                   "labels are ignored by sum",
+			endpoint:               "/sum?header=true&labels=true&format=json",
+			filePath:               "testdata/labeled.csv",
+			expectedStatusCode:     200,
+			expectedContentType:    "application/json",
+			expectedResponseSubstr: `{"value":45}`,
+		},
+		{
+			name:                   "flatten as JSON",
+			endpoint:               "/flatten?format=json",
+			filePath:               "testdata/valid_2_to_2.csv",
+			expectedStatusCode:     200,
+			expectedResponseSubstr: `{"values":[0,1,2,3]}`,
+		},
+		{
+			name:                   "header row without header option",
+			endpoint:               "/echo",
+			filePath:               "testdata/with_header.csv",
+			expectedStatusCode:     400,
+			expectedResponseSubstr: "matrix has a header row or non-integer value at row 1, column 1",
+		},
+		{
+			name:                   "invalid labels value",
+			endpoint:               "/echo?labels=yes",
+			filePath:               "testdata/labeled.csv",
+			expectedStatusCode:     400,
+			expectedResponseSubstr: "invalid labels \"yes\": use true or false",
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			req := createCSVRequest(s.T(), tc.endpoint, tc.filePath)
+			if tc.accept != "" {
+				req.Header.Set("Accept", tc.accept)
+			}
+			w := httptest.NewRecorder()
+			NewRouter(Config{}, discardLogger()).ServeHTTP(w, req)
+
+			resp := w.Result()
+			body, _ := io.ReadAll(resp.Body)
+
+			s.Equal(tc.expectedStatusCode, resp.StatusCode)
+			s.Contains(string(body), tc.expectedResponseSubstr)
+			if tc.expectedContentType != "" {
+				s.Equal(tc.expectedContentType, resp.Header.Get("Content-Type"))
+			}
+		})
+	}
+}
+
+// Test for the prefixes and base query parameters
+func (s *EndpointTestSuite) TestBases() {
+	tests := []struct {
+		name                   string
+		endpoint               string
+		filePath               string
+		expectedStatusCode     int
+		expectedResponseSubstr string
+	}{
+		{
+			name:                   "prefixed literals",
+			endpoint:               "/echo?prefixes=true",
+			filePath:               "testdata/bitmask.csv",
+			expectedStatusCode:     200,
+			expectedResponseSubstr: "255,10\n15,1000\n",
+		},
+		{
+			name:                   "prefixed literals are rejected by default",
+			endpoint:               "/echo",
+			filePath:               "testdata/bitmask.csv",
+			expectedStatusCode:     400,
+			expectedResponseSubstr: "matrix has a header row or non-integer value at row 1, column 1: \"0xFF\" has a base prefix",
+		},
+		{
+			name:                   "hexadecimal output",
+			endpoint:               "/invert?prefixes=true&base=16",
+			filePath:               "testdata/bitmask.csv",
+			expectedStatusCode:     200,
+			expectedResponseSubstr: "0xff,0xf\n0xa,0x3e8\n",
+		},
+		{
+			name:                   "binary flatten",
+			endpoint:               "/flatten?base=2",
+			filePath:               "testdata/valid_2_to_2.csv",
+			expectedStatusCode:     200,
+			expectedResponseSubstr: "0b0,0b1,0b10,0b11\n",
+		},
+		{
+			name:                   "octal sum",
+			endpoint:               "/sum?base=8",
+			filePath:               "testdata/valid_4_to_4.csv",
+			expectedStatusCode:     200,
+			expectedResponseSubstr: "0o26\n",
+		},
+		{
+			name:                   "invalid base",
+			endpoint:               "/echo?base=3",
+			filePath:               "testdata/valid_2_to_2.csv",
+			expectedStatusCode:     400,
+			expectedResponseSubstr: "invalid base \"3\": use 2, 8, 10 or 16",
+		},
+		{
+			name:                   "base with JSON output",
+			endpoint:               "/echo?base=16&format=json",
+			filePath:               "testdata/valid_2_to_2.csv",
+			expectedStatusCode:     400,
+			expectedResponseSubstr: "base 16 is only supported for csv, table, latex, markdown or html output",
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			req := createCSVRequest(s.T(), tc.endpoint, tc.filePath)
+			w := httptest.NewRecorder()
+			NewRouter(Config{}, discardLogger()).ServeHTTP(w, req)
+
+			resp := w.Result()
+			body, _ := io.ReadAll(resp.Body)
+
+			s.Equal(tc.expectedStatusCode, resp.StatusCode)
+			s.Contains(string(body), tc.expectedResponseSubstr)
+		})
+	}
+}
+
+// Test for .npy uploads and .npy output
+func (s *EndpointTestSuite) TestNPY() {
+	tests := []struct {
+		name                   string
+		endpoint               string
+		filePath               string
+		accept                 string
+		expectedStatusCode     int
+		expectedContentType    string
+		expectedResponseSubstr string
+		expectedMatrix         [][]int64
+	}{
+		{
+			name:                   "int64 upload echoed as csv",
+			endpoint:               "/echo",
+			filePath:               "testdata/valid_int64.npy",
+			expectedStatusCode:     200,
+			expectedResponseSubstr: "1,2,3\n4,5,6\n7,8,9\n",
+		},
+		{
+			name:                   "float64 fortran upload sum",
+			endpoint:               "/sum",
+			filePath:               "testdata/valid_float64_fortran.npy",
+			expectedStatusCode:     200,
+			expectedResponseSubstr: "45\n",
+		},
+		{
+			name:                "csv upload inverted as npy with accept header",
+			endpoint:            "/invert",
+			filePath:            "testdata/valid_3_to_3.csv",
+			accept:              "application/x-npy",
+			expectedStatusCode:  200,
+			expectedContentType: "application/x-npy",
+			expectedMatrix:      [][]int64{{1, 4, 7}, {2, 5, 8}, {3, 6, 9}},
+		},
+		{
+			name:                "fortran upload echoed as npy",
+			endpoint:            "/echo?format=npy",
+			filePath:            "testdata/valid_float64_fortran.npy",
+			expectedStatusCode:  200,
+			expectedContentType: "application/x-npy",
+			expectedMatrix:      [][]int64{{1, 2, 3}, {4, 5, 6}, {7, 8, 9}},
+		},
+		{
+			name:                   "flatten as npy",
+			endpoint:               "/flatten?format=npy",
+			filePath:               "testdata/valid_int64.npy",
+			expectedStatusCode:     200,
+			expectedContentType:    "application/x-npy",
+			expectedResponseSubstr: "'shape': (9,)",
+		},
+		{
+			name:                   "multiply as npy",
+			endpoint:               "/multiply?format=npy",
+			filePath:               "testdata/valid_int64.npy",
+			expectedStatusCode:     200,
+			expectedContentType:    "application/x-npy",
+			expectedResponseSubstr: "'shape': ()",
+		},
+		{
+			name:                   "not square npy",
+			endpoint:               "/echo",
+			filePath:               "testdata/not_square.npy",
+			expectedStatusCode:     400,
+			expectedResponseSubstr: "matrix is not square: 2 rows and 3 columns",
+		},
+		{
+			name:                   "fractional npy value",
+			endpoint:               "/sum",
+			filePath:               "testdata/fraction_float64.npy",
+			expectedStatusCode:     400,
+			expectedResponseSubstr: "matrix value at row 1, column 2 is not an integer: 2.5 is not a whole number",
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			req := createCSVRequest(s.T(), tc.endpoint, tc.filePath)
+			if tc.accept != "" {
+				req.Header.Set("Accept", tc.accept)
+			}
+			w := httptest.NewRecorder()
+			NewRouter(Config{}, discardLogger()).ServeHTTP(w, req)
+
+			resp := w.Result()
+			body, _ := io.ReadAll(resp.Body)
+
+			s.Equal(tc.expectedStatusCode, resp.StatusCode)
+			s.Contains(string(body), tc.expectedResponseSubstr)
+			if tc.expectedContentType != "" {
+				s.Equal(tc.expectedContentType, resp.Header.Get("Content-Type"))
+			}
+			if tc.expectedMatrix != nil {
+				m, err := matrix.ReadNPY(bytes.NewReader(body))
+				s.Require().NoError(err)
+				s.Equal(tc.expectedMatrix, m.Values)
+			}
+		})
+	}
+}
+
+// Test for the properties endpoint
+func (s *EndpointTestSuite) TestPropertiesEndpoint() {
+	tests := []struct {
+		name               string
+		endpoint           string
+		filePath           string
+		expectedStatusCode int
+		expectedResponse   string
+	}{
+		{
+			name:               "singular 3*3 matrix",
+			endpoint:           "/properties",
+			filePath:           "testdata/valid_3_to_3.csv",
+			expectedStatusCode: 200,
+			expectedResponse:   `{"trace":15,"rank":2,"determinant":0,"symmetric":false,"skew_symmetric":false,"diagonal":false,"upper_triangular":false,"lower_triangular":false,"identity":false,"orthogonal":false,"permutation":false}`,
+		},
+		{
+			name:               "invertible 4*4 matrix",
+			endpoint:           "/properties",
+			filePath:           "testdata/valid_4_to_4.csv",
+			expectedStatusCode: 200,
+			expectedResponse:   `{"trace":9,"rank":4,"determinant":-195,"symmetric":false,"skew_symmetric":false,"diagonal":false,"upper_triangular":false,"lower_triangular":false,"identity":false,"orthogonal":false,"permutation":false}`,
+		},
+		{
+			name:               "identity matrix",
+			endpoint:           "/properties?matrix=1,0;0,1",
+			filePath:           "testdata/valid_2_to_2.csv",
+			expectedStatusCode: 200,
+			expectedResponse:   `{"trace":2,"rank":2,"determinant":1,"symmetric":true,"skew_symmetric":false,"diagonal":true,"upper_triangular":true,"lower_triangular":true,"identity":true,"orthogonal":true,"permutation":true}`,
+		},
+		{
+			name:               "rotation matrix",
+			endpoint:           "/properties?matrix=0,-1;1,0",
+			filePath:           "testdata/valid_2_to_2.csv",
+			expectedStatusCode: 200,
+			expectedResponse:   `{"trace":0,"rank":2,"determinant":1,"symmetric":false,"skew_symmetric":true,"diagonal":false,"upper_triangular":false,"lower_triangular":false,"identity":false,"orthogonal":true,"permutation":false}`,
+		},
+		{
+			name:               "not square matrix",
+			endpoint:           "/properties",
+			filePath:           "testdata/more_rows_than_cols.csv",
+			expectedStatusCode: 400,
+			expectedResponse:   "matrix is not square",
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			req := createCSVRequest(s.T(), tc.endpoint, tc.filePath)
+			w := httptest.NewRecorder()
+			NewRouter(Config{}, discardLogger()).ServeHTTP(w, req)
+
+			resp := w.Result()
+			body, _ := io.ReadAll(resp.Body)
+
+			s.Equal(tc.expectedStatusCode, resp.StatusCode)
+			if tc.expectedStatusCode == 200 {
+				s.Equal("application/json", resp.Header.Get("Content-Type"))
+				s.JSONEq(tc.expectedResponse, string(body))
+			} else {
+				s.Contains(string(body), tc.expectedResponse)
+			}
+		})
+	}
+}
+
+// Test for the rref endpoint
+func (s *EndpointTestSuite) TestRREFEndpoint() {
+	tests := []struct {
+		name                   string
+		endpoint               string
+		filePath               string
+		accept                 string
+		expectedStatusCode     int
+		expectedResponseSubstr string
+	}{
+		{
+			name:                   "rref as csv",
+			endpoint:               "/rref",
+			filePath:               "testdata/valid_3_to_3.csv",
+			expectedStatusCode:     200,
+			expectedResponseSubstr: "1,0,-1\n0,1,2\n0,0,0\n",
+		},
+		{
+			name:                   "rref steps as csv",
+			endpoint:               "/rref?steps=true",
+			filePath:               "testdata/valid_3_to_3.csv",
+			expectedStatusCode:     200,
+			expectedResponseSubstr: "start\n1,2,3\n4,5,6\n7,8,9\n\nR2 <- R2 - 4*R1\n1,2,3\n0,-3,-6\n7,8,9\n",
+		},
+		{
+			name:                   "rref as json",
+			endpoint:               "/rref?matrix=2,1;4,2",
+			filePath:               "testdata/valid_2_to_2.csv",
+			accept:                 "application/json",
+			expectedStatusCode:     200,
+			expectedResponseSubstr: `{"values":[["1","1/2"],["0","0"]],"rank":1,"pivot_columns":[1]}`,
+		},
+		{
+			name:                   "rref steps as json",
+			endpoint:               "/rref?matrix=0,1;1,0&format=json&steps=true",
+			filePath:               "testdata/valid_2_to_2.csv",
+			expectedStatusCode:     200,
+			expectedResponseSubstr: `"steps":[{"operation":"swap","description":"R1 \u003c-\u003e R2","row":1,"source":2,"values":[["1","0"],["0","1"]]}]`,
+		},
+		{
+			name:                   "invalid steps",
+			endpoint:               "/rref?steps=all",
+			filePath:               "testdata/valid_2_to_2.csv",
+			expectedStatusCode:     400,
+			expectedResponseSubstr: "invalid steps \"all\": use true or false",
+		},
+		{
+			name:                   "unsupported format",
+			endpoint:               "/rref?format=npy",
+			filePath:               "testdata/valid_2_to_2.csv",
+			expectedStatusCode:     400,
+			expectedResponseSubstr: "format \"npy\" is not supported for this result: use csv or json",
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			req := createCSVRequest(s.T(), tc.endpoint, tc.filePath)
+			if tc.accept != "" {
+				req.Header.Set("Accept", tc.accept)
+			}
+			w := httptest.NewRecorder()
+			NewRouter(Config{}, discardLogger()).ServeHTTP(w, req)
+
+			resp := w.Result()
+			body, _ := io.ReadAll(resp.Body)
+
+			s.Equal(tc.expectedStatusCode, resp.StatusCode)
+			s.Contains(string(body), tc.expectedResponseSubstr)
+		})
+	}
+}
+
+// identityQuery returns the n*n identity matrix as a matrix query parameter value.
+func identityQuery(n int) string {
+	rows := make([]string, n)
+	for i := range rows {
+		row := make([]string, n)
+		for j := range row {
+			row[j] = "0"
+		}
+		row[i] = "1"
+		rows[i] = strings.Join(row, ",")
+	}
+	return strings.Join(rows, ";")
+}
+
+// Test the size limit of the rref endpoint and that it stops when the client goes away
+func (s *EndpointTestSuite) TestRREFLimits() {
+	req := httptest.NewRequest("POST", "/rref?matrix="+identityQuery(maxRREFSize+1), nil)
+	w := httptest.NewRecorder()
+	NewRouter(Config{}, discardLogger()).ServeHTTP(w, req)
+	s.Equal(413, w.Code)
+	s.Contains(w.Body.String(), "matrix of 65x65 values is too large for this operation, the limit is 64x64")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req = httptest.NewRequestWithContext(ctx, "POST", "/rref?matrix="+identityQuery(maxRREFSize), nil)
+	w = httptest.NewRecorder()
+	NewRouter(Config{}, discardLogger()).ServeHTTP(w, req)
+	s.Equal(503, w.Code)
+	s.Contains(w.Body.String(), "request canceled: context canceled")
+}
+
+// Test for the decomposition endpoints
+func (s *EndpointTestSuite) TestDecomposeEndpoints() {
+	tests := []struct {
+		name                   string
+		endpoint               string
+		accept                 string
+		expectedStatusCode     int
+		expectedResponseSubstr string
+	}{
+		{
+			name:                   "lu as json",
+			endpoint:               "/decompose/lu?matrix=1,2;3,4",
+			expectedStatusCode:     200,
+			expectedResponseSubstr: `"P":[[0,1],[1,0]]`,
+		},
+		{
+			name:                   "qr as json",
+			endpoint:               "/decompose/qr?matrix=3,0;4,5",
+			expectedStatusCode:     200,
+			expectedResponseSubstr: `"R":[[5,4],[0,3]]`,
+		},
+		{
+			name:                   "cholesky as json",
+			endpoint:               "/decompose/cholesky?matrix=4,12,-16;12,37,-43;-16,-43,98",
+			expectedStatusCode:     200,
+			expectedResponseSubstr: `{"L":[[2,0,0],[6,1,0],[-8,5,3]]}`,
+		},
+		{
+			name:                   "cholesky of a matrix that is not symmetric",
+			endpoint:               "/decompose/cholesky?matrix=1,2;3,4",
+			expectedStatusCode:     422,
+			expectedResponseSubstr: "matrix is not symmetric: value at row 1, column 2 is 2 but value at row 2, column 1 is 3",
+		},
+		{
+			name:                   "cholesky of a matrix that is not positive-definite",
+			endpoint:               "/decompose/cholesky?matrix=1,2;2,1",
+			expectedStatusCode:     422,
+			expectedResponseSubstr: "matrix is not positive-definite: leading minor of order 2 is not positive",
+		},
+		{
+			name:                   "invalid format",
+			endpoint:               "/decompose/qr?matrix=1,2;3,4&format=csv",
+			expectedStatusCode:     400,
+			expectedResponseSubstr: "invalid format \"csv\": use json or multipart",
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			req := httptest.NewRequest("POST", tc.endpoint, nil)
+			w := httptest.NewRecorder()
+			NewRouter(Config{}, discardLogger()).ServeHTTP(w, req)
+
+			resp := w.Result()
+			body, _ := io.ReadAll(resp.Body)
+
+			s.Equal(tc.expectedStatusCode, resp.StatusCode)
+			s.Contains(string(body), tc.expectedResponseSubstr)
+		})
+	}
+}
+
+// Test for decompositions returned as a multipart response of csv files
+func (s *EndpointTestSuite) TestDecomposeMultipart() {
+	req := httptest.NewRequest("POST", "/decompose/lu?matrix=1,2;3,4", nil)
+	req.Header.Set("Accept", "multipart/mixed")
+	w := httptest.NewRecorder()
+	NewRouter(Config{}, discardLogger()).ServeHTTP(w, req)
+
+	resp := w.Result()
+	s.Require().Equal(200, resp.StatusCode)
+	mediaType, params, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	s.Require().NoError(err)
+	s.Equal("multipart/mixed", mediaType)
+
+	parts := map[string]string{}
+	reader := multipart.NewReader(resp.Body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		s.Require().NoError(err)
+		s.Equal("text/csv", part.Header.Get("Content-Type"))
+		body, _ := io.ReadAll(part)
+		parts[part.FileName()] = string(body)
+	}
+	s.Equal(map[string]string{
+		"P.csv": "0,1\n1,0\n",
+		"L.csv": "1,0\n0.3333333333333333,1\n",
+		"U.csv": "3,4\n0,0.6666666666666667\n",
+	}, parts)
+}
+
+// Test for the eigen endpoint
+func (s *EndpointTestSuite) TestEigenEndpoint() {
+	tests := []struct {
+		name                   string
+		endpoint               string
+		expectedStatusCode     int
+		expectedResponseSubstr string
+	}{
+		{
+			name:                   "complex pair",
+			endpoint:               "/eigen?matrix=0,-1;1,0",
+			expectedStatusCode:     200,
+			expectedResponseSubstr: `"eigenvalues":[{"real":0,"imag":1},{"real":0,"imag":-1}]`,
+		},
+		{
+			name:                   "transition matrix",
+			endpoint:               "/eigen?matrix=0,1;1,0",
+			expectedStatusCode:     200,
+			expectedResponseSubstr: `"eigenvalues":[{"real":1,"imag":0},{"real":-1,"imag":0}]`,
+		},
+		{
+			name:                   "spectral radius",
+			endpoint:               "/eigen?matrix=2,0;0,-3",
+			expectedStatusCode:     200,
+			expectedResponseSubstr: `"spectral_radius":3`,
+		},
+		{
+			name:                   "zero 2*2",
+			endpoint:               "/eigen?matrix=0,0;0,0",
+			expectedStatusCode:     200,
+			expectedResponseSubstr: `"eigenvalues":[{"real":0,"imag":0},{"real":0,"imag":0}]`,
+		},
+		{
+			name:                   "zero 3*3",
+			endpoint:               "/eigen?matrix=0,0,0;0,0,0;0,0,0",
+			expectedStatusCode:     200,
+			expectedResponseSubstr: `"spectral_radius":0,"iterations":0}`,
+		},
+		{
+			name:                   "no convergence",
+			endpoint:               "/eigen?matrix=1,2,3;4,5,6;7,8,9&max_iterations=1",
+			expectedStatusCode:     422,
+			expectedResponseSubstr: "eigenvalues did not converge after 1 iterations: raise max_iterations or tolerance",
+		},
+		{
+			name:                   "invalid tolerance",
+			endpoint:               "/eigen?matrix=1,2;3,4&tolerance=0",
+			expectedStatusCode:     400,
+			expectedResponseSubstr: "invalid tolerance \"0\": use a number between 0 and 1, such as 1e-12",
+		},
+		{
+			name:                   "invalid max iterations",
+			endpoint:               "/eigen?matrix=1,2;3,4&max_iterations=many",
+			expectedStatusCode:     400,
+			expectedResponseSubstr: "invalid max_iterations \"many\": use an integer from 1 to 10000",
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			req := httptest.NewRequest("POST", tc.endpoint, nil)
+			w := httptest.NewRecorder()
+			NewRouter(Config{}, discardLogger()).ServeHTTP(w, req)
+
+			resp := w.Result()
+			body, _ := io.ReadAll(resp.Body)
+
+			s.Equal(tc.expectedStatusCode, resp.StatusCode)
+			s.Contains(string(body), tc.expectedResponseSubstr)
+		})
+	}
+}
+
+// Test for the power endpoint and the mod parameter
+func (s *EndpointTestSuite) TestPowerAndModEndpoints() {
+	tests := []struct {
+		name                   string
+		endpoint               string
+		expectedStatusCode     int
+		expectedResponseSubstr string
+	}{
+		{
+			name:                   "power as csv",
+			endpoint:               "/power?matrix=1,1;1,0&n=10",
+			expectedStatusCode:     200,
+			expectedResponseSubstr: "89,55\n55,34\n",
+		},
+		{
+			name:                   "power larger than int64 as json",
+			endpoint:               "/power?matrix=1,1;1,0&n=100&format=json",
+			expectedStatusCode:     200,
+			expectedResponseSubstr: `{"values":[[573147844013817084101,354224848179261915075],[354224848179261915075,218922995834555169026]]}`,
+		},
+		{
+			name:                   "hexadecimal power",
+			endpoint:               "/power?matrix=1,1;1,0&n=10&base=16",
+			expectedStatusCode:     200,
+			expectedResponseSubstr: "0x59,0x37\n0x37,0x22\n",
+		},
+		{
+			name:                   "power modulo a prime",
+			endpoint:               "/power?matrix=1,1;1,0&n=100&mod=1000000007",
+			expectedStatusCode:     200,
+			expectedResponseSubstr: "782204094,687995182\n687995182,94208912\n",
+		},
+		{
+			name:                   "huge power without mod",
+			endpoint:               "/power?matrix=1,1;1,0&n=100000000",
+			expectedStatusCode:     413,
+			expectedResponseSubstr: "values of the matrix to the power 100000000 could have up to 100000000 bits, the limit is 4194304 bits for a 2x2 matrix: use mod to keep them bounded",
+		},
+		{
+			name:                   "power as a table",
+			endpoint:               "/power?matrix=1,1;1,0&n=10&format=table",
+			expectedStatusCode:     400,
+			expectedResponseSubstr: "format \"table\" is not supported for this result: use csv or json",
+		},
+		{
+			name:                   "missing exponent",
+			endpoint:               "/power?matrix=1,1;1,0",
+			expectedStatusCode:     400,
+			expectedResponseSubstr: "missing exponent: set n to a non-negative integer, such as n=2",
+		},
+		{
+			name:                   "negative exponent",
+			endpoint:               "/power?matrix=1,1;1,0&n=-1",
+			expectedStatusCode:     400,
+			expectedResponseSubstr: "invalid n \"-1\": use an integer from 0 to 18446744073709551615",
+		},
+		{
+			name:                   "sum larger than int64",
+			endpoint:               "/sum?matrix=9223372036854775807,1;1,1",
+			expectedStatusCode:     200,
+			expectedResponseSubstr: "9223372036854775810\n",
+		},
+		{
+			name:                   "sum modulo",
+			endpoint:               "/sum?matrix=1,2;3,-4&mod=5",
+			expectedStatusCode:     200,
+			expectedResponseSubstr: "2\n",
+		},
+		{
+			name:                   "product modulo",
+			endpoint:               "/multiply?matrix=1,2;3,-4&mod=5&format=json",
+			expectedStatusCode:     200,
+			expectedResponseSubstr: `{"value":1}`,
+		},
+		{
+			name:                   "determinant modulo",
+			endpoint:               "/properties?matrix=2,1;1,5&mod=4",
+			expectedStatusCode:     200,
+			expectedResponseSubstr: `{"trace":3,"rank":2,"determinant":1,`,
+		},
+		{
+			name:                   "invalid mod",
+			endpoint:               "/sum?matrix=1,2;3,4&mod=1",
+			expectedStatusCode:     400,
+			expectedResponseSubstr: "invalid mod \"1\": use an integer from 2 to 9223372036854775807",
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			req := httptest.NewRequest("POST", tc.endpoint, nil)
+			w := httptest.NewRecorder()
+			NewRouter(Config{}, discardLogger()).ServeHTTP(w, req)
+
+			resp := w.Result()
+			body, _ := io.ReadAll(resp.Body)
+
+			s.Equal(tc.expectedStatusCode, resp.StatusCode)
+			s.Contains(string(body), tc.expectedResponseSubstr)
+		})
+	}
+}
+
+// Test for the charpoly endpoint
+func (s *EndpointTestSuite) TestCharPolyEndpoint() {
+	tests := []struct {
+		name                   string
+		endpoint               string
+		expectedStatusCode     int
+		expectedResponseSubstr string
+	}{
+		{
+			name:                   "characteristic polynomial",
+			endpoint:               "/charpoly?matrix=1,2,3;4,5,6;7,8,9",
+			expectedStatusCode:     200,
+			expectedResponseSubstr: `{"characteristic":{"degree":3,"coefficients":[1,-15,-18,0],"polynomial":"x^3 - 15x^2 - 18x"}}`,
+		},
+		{
+			name:               "minimal polynomial",
+			endpoint:           "/charpoly?matrix=2,1,0;0,2,0;0,0,2&minimal=true",
+			expectedStatusCode: 200,
+			expectedResponseSubstr: `{"characteristic":{"degree":3,"coefficients":[1,-6,12,-8],"polynomial":"x^3 - 6x^2 + 12x - 8"},` +
+				`"minimal":{"degree":2,"coefficients":[1,-4,4],"polynomial":"x^2 - 4x + 4"}}`,
+		},
+		{
+			name:                   "characteristic polynomial of a large matrix",
+			endpoint:               "/charpoly?matrix=" + identityQuery(maxMinimalPolynomialSize+1),
+			expectedStatusCode:     200,
+			expectedResponseSubstr: `"degree":33`,
+		},
+		{
+			name:                   "minimal polynomial of a large matrix",
+			endpoint:               "/charpoly?minimal=true&matrix=" + identityQuery(maxMinimalPolynomialSize+1),
+			expectedStatusCode:     413,
+			expectedResponseSubstr: "matrix of 33x33 values is too large for this operation, the limit is 32x32",
+		},
+		{
+			name:                   "invalid minimal",
+			endpoint:               "/charpoly?matrix=1,2;3,4&minimal=maybe",
+			expectedStatusCode:     400,
+			expectedResponseSubstr: "invalid minimal \"maybe\": use true or false",
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			req := httptest.NewRequest("POST", tc.endpoint, nil)
+			w := httptest.NewRecorder()
+			NewRouter(Config{}, discardLogger()).ServeHTTP(w, req)
+
+			resp := w.Result()
+			body, _ := io.ReadAll(resp.Body)
+
+			s.Equal(tc.expectedStatusCode, resp.StatusCode)
+			s.Contains(string(body), tc.expectedResponseSubstr)
+		})
+	}
+}
+
+// Test that a result that cannot be encoded as JSON is a 500 error rather than an empty response
+func (s *EndpointTestSuite) TestWriteJSONError() {
+	req := httptest.NewRequest("POST", "/eigen", nil)
+	w := httptest.NewRecorder()
+	writeJSON(w, req, map[string]float64{"value": math.NaN()})
+
+	resp := w.Result()
+	body, _ := io.ReadAll(resp.Body)
+	s.Equal(500, resp.StatusCode)
+	s.Contains(string(body), "failed to encode the result: json: unsupported value: NaN")
+}
+
 // Run all tests
 func TestEndpointTestSuite(t *testing.T) {
 	suite.Run(t, new(EndpointTestSuite))
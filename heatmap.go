@@ -0,0 +1,343 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"league_code_test/matrix"
+)
+
+// Image formats of the heatmaps returned by RenderHandler.
+const (
+	imagePNG = "png"
+	imageSVG = "svg"
+)
+
+const (
+	// maxHeatmapPixels caps the width and height of a heatmap, set with the cell query parameter
+	maxHeatmapPixels = 4096
+	// maxSVGCells caps the cells of an SVG heatmap, which has an element per cell
+	maxSVGCells = 1 << 18
+	// heatmapPadding surrounds the heatmap and separates it from the legend
+	heatmapPadding = 8
+	// legendWidth is the width of the color bar of the legend
+	legendWidth = 16
+	// legendLabelGap separates the color bar from its labels
+	legendLabelGap = 4
+	// glyphScale is the size in pixels of a dot of the PNG digit font
+	glyphScale = 2
+	// textHeight is the height of the digits drawn by drawText and of the SVG legend labels
+	textHeight = 5 * glyphScale
+)
+
+// colorScale maps positions from 0 to 1 to colors, interpolated between evenly spaced stops.
+type colorScale []color.RGBA
+
+// colorScales are the color scales of heatmaps by name. The diverging scale is centered on
+// zero, the others span the values from the minimum to the maximum.
+var colorScales = map[string]colorScale{
+	"viridis":   {{68, 1, 84, 255}, {59, 82, 139, 255}, {33, 145, 140, 255}, {94, 201, 98, 255}, {253, 231, 37, 255}},
+	"grayscale": {{0, 0, 0, 255}, {255, 255, 255, 255}},
+	"heat":      {{0, 0, 0, 255}, {178, 34, 34, 255}, {255, 165, 0, 255}, {255, 255, 224, 255}},
+	"diverging": {{59, 76, 192, 255}, {221, 221, 221, 255}, {180, 4, 38, 255}},
+}
+
+// colorScaleNames are the names of the color scales in the order of the error messages.
+var colorScaleNames = []string{"viridis", "grayscale", "heat", "diverging"}
+
+// at returns the color at the position t, clamped between 0 and 1.
+func (cs colorScale) at(t float64) color.RGBA {
+	t = min(max(t, 0), 1) * float64(len(cs)-1)
+	i := min(int(t), len(cs)-2)
+	f := t - float64(i)
+	lerp := func(a, b uint8) uint8 { return uint8(float64(a) + (float64(b)-float64(a))*f + 0.5) }
+	from, to := cs[i], cs[i+1]
+	return color.RGBA{lerp(from.R, to.R), lerp(from.G, to.G), lerp(from.B, to.B), 255}
+}
+
+// heatmapOptions configure the heatmap returned by RenderHandler.
+type heatmapOptions struct {
+	format string
+	scale  colorScale
+	// centered maps zero to the middle of the scale, for the diverging scale
+	centered bool
+	// values writes the value of every cell over its color
+	values bool
+	legend bool
+	// cell is the width and height of a cell in pixels
+	cell int
+}
+
+// requestHeatmapOptions returns the heatmap options set by the query parameters of the request
+// for a matrix of the size:
+//   - format: png (default) or svg, also selected with Accept: image/svg+xml
+//   - scale: viridis (default), grayscale, heat or diverging
+//   - values: true to write the values in the cells, false by default
+//   - legend: false to leave out the color bar with the minimum and maximum values
+//   - cell: size of the cells in pixels, by default so that the heatmap is about 512 pixels wide
+func requestHeatmapOptions(r *http.Request, size int) (heatmapOptions, error) {
+	opts := heatmapOptions{format: imagePNG, scale: colorScales["viridis"], legend: true}
+	query := r.URL.Query()
+
+	switch format := query.Get("format"); format {
+	case "":
+		if accept := r.Header.Get("Accept"); acceptQuality(accept, "image/svg+xml") > acceptQuality(accept, "image/png") {
+			opts.format = imageSVG
+		}
+	case imagePNG, imageSVG:
+		opts.format = format
+	default:
+		return opts, fmt.Errorf("invalid format %q: use png or svg", format)
+	}
+	if name := query.Get("scale"); name != "" {
+		scale, ok := colorScales[name]
+		if !ok {
+			return opts, fmt.Errorf("invalid scale %q: use %s", name, joinOr(colorScaleNames))
+		}
+		opts.scale, opts.centered = scale, name == "diverging"
+	}
+	for _, option := range []struct {
+		name  string
+		value *bool
+	}{{"values", &opts.values}, {"legend", &opts.legend}} {
+		if value := query.Get(option.name); value != "" {
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return opts, fmt.Errorf("invalid %s %q: use true or false", option.name, value)
+			}
+			*option.value = b
+		}
+	}
+
+	if value := query.Get("cell"); value != "" {
+		cell, err := strconv.Atoi(value)
+		if err != nil || cell < 1 || cell > 256 {
+			return opts, fmt.Errorf("invalid cell %q: use an integer from 1 to 256", value)
+		}
+		opts.cell = cell
+	} else {
+		opts.cell = min(max(512/max(size, 1), 1), 32)
+	}
+	return opts, nil
+}
+
+// heatmap is the layout of a heatmap image, computed once for the PNG and SVG writers.
+type heatmap struct {
+	m                  *matrix.Matrix
+	opts               heatmapOptions
+	minimum, maximum   int64
+	minLabel, maxLabel string
+	width, height      int
+	// legendX is the left of the color bar, which is legendHeight pixels high
+	legendX, legendHeight int
+}
+
+// newHeatmap lays out the heatmap of the matrix, or returns an error when it exceeds the size
+// limits.
+func newHeatmap(m *matrix.Matrix, opts heatmapOptions) (*heatmap, error) {
+	n := m.Size()
+	side := n * opts.cell
+	if side > maxHeatmapPixels {
+		return nil, fmt.Errorf("heatmap would be %d pixels wide, the limit is %d: use a smaller cell", side, maxHeatmapPixels)
+	}
+	if opts.format == imageSVG && n*n > maxSVGCells {
+		return nil, fmt.Errorf("svg heatmap would have %d cells, the limit is %d: use png", n*n, maxSVGCells)
+	}
+
+	h := &heatmap{m: m, opts: opts}
+	if n > 0 {
+		h.minimum, h.maximum = slices.Min(m.Flatten()), slices.Max(m.Flatten())
+	}
+	h.minLabel, h.maxLabel = strconv.FormatInt(h.minimum, 10), strconv.FormatInt(h.maximum, 10)
+
+	h.width, h.height = heatmapPadding+side+heatmapPadding, heatmapPadding+side+heatmapPadding
+	if opts.legend {
+		h.legendHeight = max(side, 4*textHeight)
+		h.legendX = heatmapPadding + side + 2*heatmapPadding
+		labelWidth := max(textWidth(h.minLabel), textWidth(h.maxLabel))
+		h.width = h.legendX + legendWidth + legendLabelGap + labelWidth + heatmapPadding
+		h.height = heatmapPadding + h.legendHeight + heatmapPadding
+	}
+	return h, nil
+}
+
+// position returns the position of the value on the color scale.
+func (h *heatmap) position(value float64) float64 {
+	minimum, maximum := float64(h.minimum), float64(h.maximum)
+	if h.opts.centered {
+		extent := max(math.Abs(minimum), math.Abs(maximum))
+		if extent == 0 {
+			return 0.5
+		}
+		return 0.5 + value/(2*extent)
+	}
+	if maximum == minimum {
+		return 0.5
+	}
+	return (value - minimum) / (maximum - minimum)
+}
+
+// color returns the color of the value.
+func (h *heatmap) color(value int64) color.RGBA {
+	return h.opts.scale.at(h.position(float64(value)))
+}
+
+// legendColor returns the color of the legend at the row y from its top, where the maximum is.
+func (h *heatmap) legendColor(y int) color.RGBA {
+	f := 1.0
+	if h.legendHeight > 1 {
+		f = 1 - float64(y)/float64(h.legendHeight-1)
+	}
+	return h.opts.scale.at(h.position(float64(h.minimum) + f*(float64(h.maximum)-float64(h.minimum))))
+}
+
+// textColor returns black or white, whichever is readable over the background.
+func textColor(background color.RGBA) color.RGBA {
+	luminance := 0.299*float64(background.R) + 0.587*float64(background.G) + 0.114*float64(background.B)
+	if luminance > 140 {
+		return color.RGBA{0, 0, 0, 255}
+	}
+	return color.RGBA{255, 255, 255, 255}
+}
+
+// write writes the heatmap in its image format. The image is encoded before the response starts,
+// so that an encoding failure is a 500 error rather than a truncated image.
+func (h *heatmap) write(w http.ResponseWriter, r *http.Request) {
+	var buf bytes.Buffer
+	contentType, encode := "image/png", h.writePNG
+	if h.opts.format == imageSVG {
+		contentType, encode = "image/svg+xml", h.writeSVG
+	}
+	if err := encode(&buf); err != nil {
+		writeError(w, r, fmt.Sprintf("failed to encode the image: %v", err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Write(buf.Bytes())
+}
+
+// writePNG writes the heatmap as a PNG image, with the values and the legend labels drawn with
+// a built-in digit font. Values wider than their cell are left out.
+func (h *heatmap) writePNG(w io.Writer) error {
+	img := image.NewRGBA(image.Rect(0, 0, h.width, h.height))
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+
+	cell := h.opts.cell
+	for i, row := range h.m.Values {
+		for j, value := range row {
+			x, y := heatmapPadding+j*cell, heatmapPadding+i*cell
+			background := h.color(value)
+			draw.Draw(img, image.Rect(x, y, x+cell, y+cell), &image.Uniform{background}, image.Point{}, draw.Src)
+			label := strconv.FormatInt(value, 10)
+			if h.opts.values && textWidth(label)+2 <= cell && textHeight+2 <= cell {
+				drawText(img, label, x+(cell-textWidth(label))/2, y+(cell-textHeight)/2, textColor(background))
+			}
+		}
+	}
+
+	if h.opts.legend {
+		for y := range h.legendHeight {
+			line := image.Rect(h.legendX, heatmapPadding+y, h.legendX+legendWidth, heatmapPadding+y+1)
+			draw.Draw(img, line, &image.Uniform{h.legendColor(y)}, image.Point{}, draw.Src)
+		}
+		black := color.RGBA{0, 0, 0, 255}
+		labelX := h.legendX + legendWidth + legendLabelGap
+		drawText(img, h.maxLabel, labelX, heatmapPadding, black)
+		drawText(img, h.minLabel, labelX, heatmapPadding+h.legendHeight-textHeight, black)
+	}
+
+	return png.Encode(w, img)
+}
+
+// writeSVG writes the heatmap as an SVG image with a rect per cell and a gradient legend.
+func (h *heatmap) writeSVG(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n", h.width, h.height, h.width, h.height)
+	fmt.Fprintf(bw, `<rect width="%d" height="%d" fill="#ffffff"/>`+"\n", h.width, h.height)
+
+	cell := h.opts.cell
+	fontSize := max(cell*2/5, 1)
+	for i, row := range h.m.Values {
+		for j, value := range row {
+			x, y := heatmapPadding+j*cell, heatmapPadding+i*cell
+			background := h.color(value)
+			fmt.Fprintf(bw, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s"><title>%d</title></rect>`+"\n", x, y, cell, cell, hexColor(background), value)
+			if h.opts.values {
+				fmt.Fprintf(bw, `<text x="%d" y="%d" font-family="monospace" font-size="%d" text-anchor="middle" dominant-baseline="central" fill="%s">%d</text>`+"\n",
+					x+cell/2, y+cell/2, fontSize, hexColor(textColor(background)), value)
+			}
+		}
+	}
+
+	if h.opts.legend {
+		bw.WriteString(`<defs><linearGradient id="legend" x1="0" y1="0" x2="0" y2="1">` + "\n")
+		const stops = 16
+		for k := range stops + 1 {
+			offset := float64(k) / stops
+			fmt.Fprintf(bw, `<stop offset="%g" stop-color="%s"/>`+"\n", offset, hexColor(h.legendColor(int(offset*float64(h.legendHeight-1)))))
+		}
+		bw.WriteString("</linearGradient></defs>\n")
+		fmt.Fprintf(bw, `<rect x="%d" y="%d" width="%d" height="%d" fill="url(#legend)"/>`+"\n", h.legendX, heatmapPadding, legendWidth, h.legendHeight)
+		labelX := h.legendX + legendWidth + legendLabelGap
+		fmt.Fprintf(bw, `<text x="%d" y="%d" font-family="monospace" font-size="%d" dominant-baseline="hanging">%s</text>`+"\n", labelX, heatmapPadding, textHeight, h.maxLabel)
+		fmt.Fprintf(bw, `<text x="%d" y="%d" font-family="monospace" font-size="%d">%s</text>`+"\n", labelX, heatmapPadding+h.legendHeight, textHeight, h.minLabel)
+	}
+
+	bw.WriteString("</svg>\n")
+	return bw.Flush()
+}
+
+// hexColor returns the color as #rrggbb.
+func hexColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// glyphs are the 3*5 dot patterns of the digit font of PNG heatmaps.
+var glyphs = map[rune][5]string{
+	'0': {"###", "#.#", "#.#", "#.#", "###"},
+	'1': {".#.", "##.", ".#.", ".#.", "###"},
+	'2': {"###", "..#", "###", "#..", "###"},
+	'3': {"###", "..#", "###", "..#", "###"},
+	'4': {"#.#", "#.#", "###", "..#", "..#"},
+	'5': {"###", "#..", "###", "..#", "###"},
+	'6': {"###", "#..", "###", "#.#", "###"},
+	'7': {"###", "..#", "..#", "..#", "..#"},
+	'8': {"###", "#.#", "###", "#.#", "###"},
+	'9': {"###", "#.#", "###", "..#", "###"},
+	'-': {"...", "...", "###", "...", "..."},
+}
+
+// textWidth returns the width in pixels of the text drawn by drawText.
+func textWidth(text string) int {
+	if text == "" {
+		return 0
+	}
+	return len(text)*4*glyphScale - glyphScale
+}
+
+// drawText draws the digits of the text with their top left corner at x, y.
+func drawText(img draw.Image, text string, x, y int, c color.RGBA) {
+	dot := &image.Uniform{c}
+	for k, char := range text {
+		glyph := glyphs[char]
+		for gy, line := range glyph {
+			for gx, bit := range line {
+				if bit != '#' {
+					continue
+				}
+				px, py := x+(k*4+gx)*glyphScale, y+gy*glyphScale
+				draw.Draw(img, image.Rect(px, py, px+glyphScale, py+glyphScale), dot, image.Point{}, draw.Src)
+			}
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"bytes"
+	"image/color"
+	"image/png"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"league_code_test/matrix"
+)
+
+type HeatmapTestSuite struct {
+	suite.Suite
+}
+
+// Test for the interpolation of color scales
+func (s *HeatmapTestSuite) TestColorScale() {
+	gray := colorScales["grayscale"]
+	s.Equal(color.RGBA{0, 0, 0, 255}, gray.at(0))
+	s.Equal(color.RGBA{128, 128, 128, 255}, gray.at(0.5))
+	s.Equal(color.RGBA{255, 255, 255, 255}, gray.at(1))
+	s.Equal(color.RGBA{255, 255, 255, 255}, gray.at(1.5))
+	s.Equal(colorScales["viridis"][2], colorScales["viridis"].at(0.5))
+}
+
+// Test for the position of values on linear and centered scales
+func (s *HeatmapTestSuite) TestPosition() {
+	m := &matrix.Matrix{Values: [][]int64{{-2, 0}, {4, 6}}}
+
+	h, err := newHeatmap(m, heatmapOptions{format: imagePNG, scale: colorScales["viridis"], cell: 4})
+	s.Require().NoError(err)
+	s.Equal(0.0, h.position(-2))
+	s.Equal(0.25, h.position(0))
+	s.Equal(1.0, h.position(6))
+
+	h, err = newHeatmap(m, heatmapOptions{format: imagePNG, scale: colorScales["diverging"], centered: true, cell: 4})
+	s.Require().NoError(err)
+	s.Equal(0.5, h.position(0))
+	s.Equal(1.0, h.position(6))
+	s.InDelta(1.0/3, h.position(-2), 1e-9)
+
+	constant, err := newHeatmap(&matrix.Matrix{Values: [][]int64{{7}}}, heatmapOptions{format: imagePNG, scale: colorScales["viridis"], cell: 4})
+	s.Require().NoError(err)
+	s.Equal(0.5, constant.position(7))
+}
+
+// Test for the PNG heatmap layout and colors
+func (s *HeatmapTestSuite) TestPNG() {
+	m := &matrix.Matrix{Values: [][]int64{{0, 1}, {2, 3}}}
+	h, err := newHeatmap(m, heatmapOptions{format: imagePNG, scale: colorScales["grayscale"], cell: 10})
+	s.Require().NoError(err)
+
+	var buf bytes.Buffer
+	s.Require().NoError(h.writePNG(&buf))
+	img, err := png.Decode(&buf)
+	s.Require().NoError(err)
+
+	s.Equal(2*heatmapPadding+20, img.Bounds().Dx())
+	s.Equal(2*heatmapPadding+20, img.Bounds().Dy())
+	s.Equal(color.RGBA{0, 0, 0, 255}, color.RGBAModel.Convert(img.At(heatmapPadding+1, heatmapPadding+1)))
+	s.Equal(color.RGBA{255, 255, 255, 255}, color.RGBAModel.Convert(img.At(heatmapPadding+15, heatmapPadding+15)))
+	s.Equal(color.RGBA{85, 85, 85, 255}, color.RGBAModel.Convert(img.At(heatmapPadding+15, heatmapPadding+1)))
+}
+
+// Test for the heatmaps through the router
+func (s *HeatmapTestSuite) TestRender() {
+	tests := []struct {
+		name                   string
+		endpoint               string
+		accept                 string
+		expectedStatusCode     int
+		expectedContentType    string
+		expectedResponseSubstr string
+	}{
+		{
+			name:                   "png by default",
+			endpoint:               "/render?values=true",
+			expectedStatusCode:     200,
+			expectedContentType:    "image/png",
+			expectedResponseSubstr: "\x89PNG",
+		},
+		{
+			name:                   "svg format",
+			endpoint:               "/render?format=svg&scale=grayscale",
+			expectedStatusCode:     200,
+			expectedContentType:    "image/svg+xml",
+			expectedResponseSubstr: `<rect x="8" y="8" width="32" height="32" fill="#000000"><title>0</title></rect>`,
+		},
+		{
+			name:                   "svg accepted with values",
+			endpoint:               "/render?values=true&cell=20",
+			accept:                 "image/svg+xml",
+			expectedStatusCode:     200,
+			expectedContentType:    "image/svg+xml",
+			expectedResponseSubstr: `dominant-baseline="central" fill="#000000">3</text>`,
+		},
+		{
+			name:                   "svg without legend",
+			endpoint:               "/render?format=svg&legend=false",
+			expectedStatusCode:     200,
+			expectedContentType:    "image/svg+xml",
+			expectedResponseSubstr: `<svg xmlns="http://www.w3.org/2000/svg" width="80" height="80" viewBox="0 0 80 80">`,
+		},
+		{
+			name:                   "invalid scale",
+			endpoint:               "/render?scale=rainbow",
+			expectedStatusCode:     400,
+			expectedResponseSubstr: "invalid scale \"rainbow\": use viridis, grayscale, heat or diverging",
+		},
+		{
+			name:                   "invalid format",
+			endpoint:               "/render?format=csv",
+			expectedStatusCode:     400,
+			expectedResponseSubstr: "invalid format \"csv\": use png or svg",
+		},
+		{
+			name:                   "invalid cell",
+			endpoint:               "/render?cell=0",
+			expectedStatusCode:     400,
+			expectedResponseSubstr: "invalid cell \"0\": use an integer from 1 to 256",
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			req := createCSVRequest(s.T(), tc.endpoint, "testdata/valid_2_to_2.csv")
+			if tc.accept != "" {
+				req.Header.Set("Accept", tc.accept)
+			}
+			w := httptest.NewRecorder()
+			NewRouter(Config{}, discardLogger()).ServeHTTP(w, req)
+
+			resp := w.Result()
+			body, _ := io.ReadAll(resp.Body)
+
+			s.Equal(tc.expectedStatusCode, resp.StatusCode)
+			if tc.expectedContentType != "" {
+				s.Equal(tc.expectedContentType, resp.Header.Get("Content-Type"))
+			}
+			s.Contains(string(body), tc.expectedResponseSubstr)
+		})
+	}
+}
+
+// Test that heatmaps wider than the limit are rejected
+func (s *HeatmapTestSuite) TestTooLarge() {
+	m := &matrix.Matrix{Values: [][]int64{{1, 2}, {3, 4}}}
+	_, err := newHeatmap(m, heatmapOptions{format: imagePNG, cell: maxHeatmapPixels})
+	s.EqualError(err, "heatmap would be 8192 pixels wide, the limit is 4096: use a smaller cell")
+}
+
+// Run all tests
+func TestHeatmapTestSuite(t *testing.T) {
+	suite.Run(t, new(HeatmapTestSuite))
+}
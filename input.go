@@ -0,0 +1,348 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+
+	"league_code_test/matrix"
+)
+
+// maxDenseValues caps how many values a sparse upload may be expanded to, in memory or in a response.
+const maxDenseValues = matrix.MaxDenseValues
+
+// maxPowerSize caps the rows of the matrices raised to a power, as every one of the up to 128
+// products of a power has cubic work over big integers.
+const maxPowerSize = 64
+
+// maxPolynomialSize caps the rows of the matrices of the characteristic and minimal polynomials,
+// which take O(n^4) operations over big integers.
+const maxPolynomialSize = 64
+
+// maxMinimalPolynomialSize caps the rows of the matrices of the minimal polynomial, which also
+// takes the greatest common divisor of the n^2 entries of the adjugate of xI - A.
+const maxMinimalPolynomialSize = 32
+
+// maxRREFSize caps the rows of the matrices of the reduced row echelon form, whose fractions are
+// ratios of minors with up to n times the digits of the values.
+const maxRREFSize = 64
+
+// maxEliminationSize caps the rows of the matrices of operations with cubic work over big
+// integers or rationals, such as the rank and determinant.
+const maxEliminationSize = 256
+
+// input is an uploaded matrix. Coordinate Matrix Market uploads stay sparse so that the echo,
+// invert, flatten, sum and multiply operations never expand large mostly-zero matrices in memory.
+type input struct {
+	dense  *matrix.Matrix
+	sparse *matrix.Sparse
+}
+
+// Size returns the number of rows, which is also the number of columns.
+func (in input) Size() int {
+	if in.sparse != nil {
+		return in.sparse.Size()
+	}
+	return in.dense.Size()
+}
+
+// Transpose returns the matrix where the columns and rows are inverted.
+func (in input) Transpose() input {
+	if in.sparse != nil {
+		return input{sparse: in.sparse.Transpose()}
+	}
+	return input{dense: in.dense.Transpose()}
+}
+
+// Sum returns the sum of the integers in the matrix.
+func (in input) Sum() *big.Int {
+	if in.sparse != nil {
+		return in.sparse.Sum()
+	}
+	return in.dense.Sum()
+}
+
+// Product returns the product of the integers in the matrix.
+func (in input) Product() *big.Int {
+	if in.sparse != nil {
+		return in.sparse.Product()
+	}
+	return in.dense.Product()
+}
+
+// SumMod returns the sum of the integers in the matrix modulo mod.
+func (in input) SumMod(mod *big.Int) *big.Int {
+	if in.sparse != nil {
+		return in.sparse.SumMod(mod)
+	}
+	return in.dense.SumMod(mod)
+}
+
+// ProductMod returns the product of the integers in the matrix modulo mod.
+func (in input) ProductMod(mod *big.Int) *big.Int {
+	if in.sparse != nil {
+		return in.sparse.ProductMod(mod)
+	}
+	return in.dense.ProductMod(mod)
+}
+
+// Matrix returns the matrix with every value stored, or an error if a sparse matrix is too large to expand.
+func (in input) Matrix() (*matrix.Matrix, error) {
+	if in.sparse == nil {
+		return in.dense, nil
+	}
+	if err := in.checkDenseSize(); err != nil {
+		return nil, err
+	}
+	return in.sparse.Dense(), nil
+}
+
+// eliminationMatrix returns the matrix with every value stored, or an error if it has more rows than
+// maxEliminationSize.
+func (in input) eliminationMatrix() (*matrix.Matrix, error) {
+	return in.limitedMatrix(maxEliminationSize)
+}
+
+// limitedMatrix returns the matrix with every value stored, or an error if it has more rows than limit.
+func (in input) limitedMatrix(limit int) (*matrix.Matrix, error) {
+	if n := in.Size(); n > limit {
+		return nil, fmt.Errorf("matrix of %dx%d values is too large for this operation, the limit is %dx%d", n, n, limit, limit)
+	}
+	return in.Matrix()
+}
+
+// rows calls fn with every row of the matrix in order, without expanding a sparse matrix at once.
+func (in input) rows(fn func(row []int64)) {
+	if in.sparse != nil {
+		in.sparse.Rows(func(_ int, row []int64) bool {
+			fn(row)
+			return true
+		})
+		return
+	}
+	for _, row := range in.dense.Values {
+		fn(row)
+	}
+}
+
+// checkDenseSize returns an error if the matrix has more values than maxDenseValues.
+func (in input) checkDenseSize() error {
+	if n := in.Size(); n > maxDenseValues/n {
+		return fmt.Errorf("matrix of %dx%d values is too large to expand, the limit is %d values: use format=mtx for sparse output", n, n, maxDenseValues)
+	}
+	return nil
+}
+
+// readInput gets the matrix from the uploaded file, raw body or matrix query parameter and
+// validates it. Input is read as Matrix Market or NumPy .npy by extension or content type,
+// and as csv otherwise. On failure it writes the 400 response and returns false.
+func readInput(w http.ResponseWriter, r *http.Request) (input, bool) {
+	parser, err := requestParser(r)
+	if err != nil {
+		writeError(w, r, err.Error(), http.StatusBadRequest)
+		return input{}, false
+	}
+
+	file, err := openUpload(r)
+	if err != nil {
+		writeError(w, r, err.Error(), http.StatusBadRequest)
+		return input{}, false
+	}
+	defer file.Close()
+
+	// Matrix Market and .npy files are validated while they are read
+	var in input
+	switch inputFormat(file) {
+	case formatMTX:
+		in.dense, in.sparse, err = matrix.ReadMatrixMarket(file)
+	case formatNPY:
+		in.dense, err = matrix.ReadNPY(file)
+	default:
+		return readCSVInput(w, r, file, parser)
+	}
+	if err != nil {
+		writeError(w, r, err.Error(), http.StatusBadRequest)
+		return input{}, false
+	}
+	recordMatrixSize(r, in.Size(), in.Size())
+	return in, true
+}
+
+// readCSVInput reads and validates the matrix of an uploaded csv file. On failure it writes
+// the 400 response and returns false.
+func readCSVInput(w http.ResponseWriter, r *http.Request, file io.Reader, parser matrix.Parser) (input, bool) {
+	// 1st Step: get matrix from csv file
+	records, normalizations, err := readCSVRecords(r, file, parser)
+	if err != nil {
+		writeError(w, r, err.Error(), http.StatusBadRequest)
+		return input{}, false
+	}
+	if len(normalizations) > 0 {
+		applied := make([]string, len(normalizations))
+		for i, normalization := range normalizations {
+			applied[i] = string(normalization)
+		}
+		w.Header().Set(NormalizationsHeader, strings.Join(applied, ", "))
+	}
+
+	// 2nd Step: validate input matrix
+	m, err := parser.FromRecords(records)
+	if err != nil {
+		writeError(w, r, err.Error(), http.StatusBadRequest)
+		return input{}, false
+	}
+
+	return input{dense: m}, true
+}
+
+// Input and output formats, selected by inputFormat and outputFormat.
+const (
+	formatCSV           = "csv"
+	formatMTX           = "mtx"
+	formatMTXArray      = "mtx-array"
+	formatMTXCoordinate = "mtx-coordinate"
+	formatNPY           = "npy"
+	formatJSON          = "json"
+	formatTable         = "table"
+	formatLaTeX         = "latex"
+	formatMarkdown      = "markdown"
+	formatHTML          = "html"
+)
+
+// inputFormat returns the format of the upload from its extension or content type.
+func inputFormat(u upload) string {
+	switch ext := u.ext(); {
+	case ext == ".mtx" || strings.HasPrefix(u.contentType, matrix.MatrixMarketContentType):
+		return formatMTX
+	case ext == ".npy" || strings.HasPrefix(u.contentType, matrix.NPYContentType):
+		return formatNPY
+	}
+	return formatCSV
+}
+
+// matrixJSON is the JSON output of a matrix, with the labels of labeled csv input.
+type matrixJSON struct {
+	Values    [][]int64 `json:"values"`
+	RowLabels []string  `json:"row_labels,omitempty"`
+	ColLabels []string  `json:"column_labels,omitempty"`
+}
+
+// writeJSON writes the value as the JSON response, or a 500 error if it cannot be encoded,
+// such as a NaN float, rather than an empty response.
+func writeJSON(w http.ResponseWriter, r *http.Request, v any) {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(v); err != nil {
+		writeError(w, r, fmt.Sprintf("failed to encode the result: %v", err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(buf.Bytes())
+}
+
+// writeFlattened writes the values of the matrix row by row as a single line or table row, as
+// a JSON list, or as a 1-dimensional array for .npy output.
+func writeFlattened(w http.ResponseWriter, r *http.Request, in input) {
+	format, err := outputFormat(r, valueFormats)
+	if err != nil {
+		writeError(w, r, err.Error(), http.StatusBadRequest)
+		return
+	}
+	base, err := outputBase(r, format)
+	if err != nil {
+		writeError(w, r, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := in.checkDenseSize(); err != nil {
+		writeError(w, r, err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	switch format {
+	case formatNPY:
+		m, _ := in.Matrix()
+		w.Header().Set("Content-Type", matrix.NPYContentType)
+		matrix.WriteNPY(w, []int{m.Size() * m.Size()}, m.Flatten())
+		return
+	case formatJSON:
+		m, _ := in.Matrix()
+		writeJSON(w, r, map[string][]int64{"values": m.Flatten()})
+		return
+	case formatTable:
+		m, _ := in.Matrix()
+		writeMatrixTable(w, r, &matrix.Matrix{Values: [][]int64{m.Flatten()}}, base)
+		return
+	}
+
+	bw := bufio.NewWriter(w)
+	first := true
+	in.rows(func(row []int64) {
+		if !first {
+			bw.WriteString(",")
+		}
+		first = false
+		bw.WriteString(formatRow(row, base))
+	})
+	bw.WriteString("\n")
+	bw.Flush()
+}
+
+// writeScalar writes a single integer result as text, as a JSON number, or as a 0-dimensional
+// array for .npy output.
+func writeScalar(w http.ResponseWriter, r *http.Request, value *big.Int) {
+	format, err := outputFormat(r, valueFormats)
+	if err != nil {
+		writeError(w, r, err.Error(), http.StatusBadRequest)
+		return
+	}
+	base, err := outputBase(r, format)
+	if err != nil {
+		writeError(w, r, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if format == formatNPY {
+		if !value.IsInt64() {
+			writeError(w, r, fmt.Sprintf("result %s does not fit in an int64 npy value", value), http.StatusNotAcceptable)
+			return
+		}
+		w.Header().Set("Content-Type", matrix.NPYContentType)
+		matrix.WriteNPY(w, nil, []int64{value.Int64()})
+		return
+	}
+	if format == formatJSON {
+		writeJSON(w, r, map[string]*big.Int{"value": value})
+		return
+	}
+
+	fmt.Fprintln(w, withBasePrefix(value.Text(base), base))
+}
+
+// writeLabeledCSV writes the matrix as csv in the base with its column labels as the header
+// row and its row labels as the first column.
+func writeLabeledCSV(w http.ResponseWriter, m *matrix.Matrix, base int) {
+	cw := csv.NewWriter(w)
+	if m.ColLabels != nil {
+		header := m.ColLabels
+		if m.RowLabels != nil {
+			header = append([]string{""}, header...)
+		}
+		cw.Write(header)
+	}
+	for i, row := range m.Values {
+		var fields []string
+		if m.RowLabels != nil {
+			fields = append(fields, m.RowLabels[i])
+		}
+		for _, num := range row {
+			fields = append(fields, formatInt(num, base))
+		}
+		cw.Write(fields)
+	}
+	cw.Flush()
+}
@@ -1,170 +1,399 @@
 package main
 
 import (
+	"expvar"
 	"fmt"
-	"math/big"
+	"log/slog"
 	"net/http"
-	"strconv"
-	"strings"
+	"os"
 )
 
 // Return the matrix as a string in matrix format
 func EchoHandler(w http.ResponseWriter, r *http.Request) {
-	// 1st Step: get matrix from csv file
-	records, err := ParseCSVFile(r)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	in, ok := readInput(w, r)
+	if !ok {
 		return
 	}
-	
-	// 2nd Step: validate input matrix
-	err = ValidateSquareMatrix(records)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+
+	writeMatrix(w, r, in)
+}
+
+// Return the matrix as a string in matrix format where the columns and rows are inverted
+func InvertHandler(w http.ResponseWriter, r *http.Request) {
+	in, ok := readInput(w, r)
+	if !ok {
 		return
 	}
 
-	// 3rd Step: build response
-	var response string
-	// Each row is a list of strings, such as ["1", "2", "3"]
-	for _, row := range records {
-		response = fmt.Sprintf("%s%s\n", response, strings.Join(row, ","))
+	writeMatrix(w, r, in.Transpose())
+}
+
+// Return the matrix as a 1 line string, with values separated by commas
+func FlattenHandler(w http.ResponseWriter, r *http.Request) {
+	in, ok := readInput(w, r)
+	if !ok {
+		return
 	}
 
-	fmt.Fprint(w, response)
+	writeFlattened(w, r, in)
 }
 
-// Return the matrix as a string in matrix format where the columns and rows are inverted
-func InvertHandler(w http.ResponseWriter, r *http.Request) {
-	// 1st Step: get matrix from csv file
-	records, err := ParseCSVFile(r)
+// Return the sum of the integers in the matrix
+func SumHandler(w http.ResponseWriter, r *http.Request) {
+	in, ok := readInput(w, r)
+	if !ok {
+		return
+	}
+
+	mod, err := queryModulus(r)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, r, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if mod != nil {
+		writeScalar(w, r, in.SumMod(mod))
+		return
+	}
+
+	writeScalar(w, r, in.Sum())
+}
+
+// Return the product of the integers in the matrix
+func MultiplyHandler(w http.ResponseWriter, r *http.Request) {
+	in, ok := readInput(w, r)
+	if !ok {
 		return
 	}
 
-	// 2nd Step: validate input matrix
-	err = ValidateSquareMatrix(records)
+	mod, err := queryModulus(r)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, r, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if mod != nil {
+		writeScalar(w, r, in.ProductMod(mod))
 		return
 	}
 
-	// 3rd Step: build the inverted matrix
-	invertedRecords := make([][]string, len(records))
-	// Each row is a list of strings, such as ["1", "2", "3"]
-	for _, row := range records {
-		for idx, num := range row {
-			invertedRecords[idx] = append(invertedRecords[idx], num)
-		}
+	writeScalar(w, r, in.Product())
+}
+
+// Return a heatmap image of the matrix as PNG or SVG
+func RenderHandler(w http.ResponseWriter, r *http.Request) {
+	in, ok := readInput(w, r)
+	if !ok {
+		return
+	}
+	m, err := in.Matrix()
+	if err != nil {
+		writeError(w, r, err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	}
+	opts, err := requestHeatmapOptions(r, m.Size())
+	if err != nil {
+		writeError(w, r, err.Error(), http.StatusBadRequest)
+		return
+	}
+	h, err := newHeatmap(m, opts)
+	if err != nil {
+		writeError(w, r, err.Error(), http.StatusRequestEntityTooLarge)
+		return
 	}
 
-	// 4th Step: build response
-	var response string
-	for _, row := range invertedRecords {
-		response = fmt.Sprintf("%s%s\n", response, strings.Join(row, ","))
+	h.write(w, r)
+}
+
+// Return the trace, rank, determinant and structural properties of the matrix as JSON
+func PropertiesHandler(w http.ResponseWriter, r *http.Request) {
+	in, ok := readInput(w, r)
+	if !ok {
+		return
+	}
+	mod, err := queryModulus(r)
+	if err != nil {
+		writeError(w, r, err.Error(), http.StatusBadRequest)
+		return
+	}
+	m, err := in.eliminationMatrix()
+	if err != nil {
+		writeError(w, r, err.Error(), http.StatusRequestEntityTooLarge)
+		return
 	}
 
-	fmt.Fprint(w, response)
+	p := m.Properties()
+	if mod != nil {
+		p.Trace.Mod(p.Trace, mod)
+		p.Determinant.Mod(p.Determinant, mod)
+	}
+	writeJSON(w, r, propertiesJSON(p))
 }
 
-// Return the matrix as a 1 line string, with values separated by commas
-func FlattenHandler(w http.ResponseWriter, r *http.Request) {
-	// 1st Step: get matrix from csv file
-	records, err := ParseCSVFile(r)
+// Return the reduced row echelon form of the matrix with exact fractions, and on request every
+// row operation that led to it
+func RREFHandler(w http.ResponseWriter, r *http.Request) {
+	in, ok := readInput(w, r)
+	if !ok {
+		return
+	}
+	format, err := outputFormat(r, []string{formatCSV, formatJSON})
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, r, err.Error(), http.StatusBadRequest)
 		return
 	}
-	
-	// 2nd Step: validate input matrix
-	err = ValidateSquareMatrix(records)
+	steps, err := queryBool(r, "steps", false)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, r, err.Error(), http.StatusBadRequest)
+		return
+	}
+	m, err := in.limitedMatrix(maxRREFSize)
+	if err == nil && steps && m.Size() > maxStepsSize {
+		err = fmt.Errorf("steps are only recorded for matrices of up to %dx%d values", maxStepsSize, maxStepsSize)
+	}
+	if err != nil {
+		writeError(w, r, err.Error(), http.StatusRequestEntityTooLarge)
 		return
 	}
 
-	// 3rd Step: flatten the matrix to a single list
-	var flattened []string
-	// Each row is a list of strings, such as ["1", "2", "3"]
-	for _, row := range records {
-		flattened = append(flattened, row...)
+	// Stop the elimination when the client goes away
+	e, err := m.RREFContext(r.Context(), steps)
+	if err != nil {
+		writeError(w, r, fmt.Sprintf("request canceled: %v", err), http.StatusServiceUnavailable)
+		return
 	}
+	writeRREF(w, r, format, m, e, steps)
+}
 
-	// 4th Step: build response
-	response := strings.Join(flattened, ",") + "\n"
-	fmt.Fprint(w, response)
+// Return the matrix to the power n, computed by repeated squaring
+func PowerHandler(w http.ResponseWriter, r *http.Request) {
+	in, ok := readInput(w, r)
+	if !ok {
+		return
+	}
+	format, err := outputFormat(r, []string{formatCSV, formatJSON})
+	if err != nil {
+		writeError(w, r, err.Error(), http.StatusBadRequest)
+		return
+	}
+	base, err := outputBase(r, format)
+	if err != nil {
+		writeError(w, r, err.Error(), http.StatusBadRequest)
+		return
+	}
+	n, err := powerExponent(r)
+	if err != nil {
+		writeError(w, r, err.Error(), http.StatusBadRequest)
+		return
+	}
+	mod, err := queryModulus(r)
+	if err != nil {
+		writeError(w, r, err.Error(), http.StatusBadRequest)
+		return
+	}
+	m, err := in.limitedMatrix(maxPowerSize)
+	if err == nil && mod == nil {
+		err = checkPowerBits(m, n)
+	}
+	if err != nil {
+		writeError(w, r, err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	writePower(w, r, format, base, m, m.Power(n, mod))
 }
 
-// Return the sum of the integers in the matrix
-func SumHandler(w http.ResponseWriter, r *http.Request) {
-	// 1st Step: get matrix from csv file
-	records, err := ParseCSVFile(r)
+// Return the characteristic polynomial of the matrix, and on request its minimal polynomial
+func CharPolyHandler(w http.ResponseWriter, r *http.Request) {
+	in, ok := readInput(w, r)
+	if !ok {
+		return
+	}
+	minimal, err := queryBool(r, "minimal", false)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, r, err.Error(), http.StatusBadRequest)
 		return
 	}
-	
-	// 2nd Step: validate input matrix
-	err = ValidateSquareMatrix(records)
+	limit := maxPolynomialSize
+	if minimal {
+		limit = maxMinimalPolynomialSize
+	}
+	m, err := in.limitedMatrix(limit)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, r, err.Error(), http.StatusRequestEntityTooLarge)
 		return
 	}
 
-	// 3rd Step: calculate and return the sum
-	sum := 0
-	for _, row := range records {
-		for _, val := range row {
-			// no need for error check as we did it already in ValidateSquareMatrix()
-			num, _ := strconv.Atoi(val)
-			sum += num
-		}
+	result := polynomialsJSON{Characteristic: newPolynomialJSON(m.CharacteristicPolynomial())}
+	if minimal {
+		result.Minimal = newPolynomialJSON(m.MinimalPolynomial())
+	}
+	writeJSON(w, r, result)
+}
+
+// Return the LU decomposition of the matrix with partial pivoting, P*A = L*U
+func LUHandler(w http.ResponseWriter, r *http.Request) {
+	m, ok := readDecompositionInput(w, r)
+	if !ok {
+		return
 	}
 
-	fmt.Fprintln(w, sum)
+	writeFactors(w, r, m.LU())
 }
 
-// Return the product of the integers in the matrix
-func MultiplyHandler(w http.ResponseWriter, r *http.Request) {
-	// 1st Step: get matrix from csv file
-	records, err := ParseCSVFile(r)
+// Return the QR decomposition of the matrix, A = Q*R
+func QRHandler(w http.ResponseWriter, r *http.Request) {
+	m, ok := readDecompositionInput(w, r)
+	if !ok {
+		return
+	}
+
+	writeFactors(w, r, m.QR())
+}
+
+// Return the Cholesky decomposition of a symmetric positive-definite matrix, A = L*Lᵀ
+func CholeskyHandler(w http.ResponseWriter, r *http.Request) {
+	m, ok := readDecompositionInput(w, r)
+	if !ok {
+		return
+	}
+	factors, err := m.Cholesky()
+	if err != nil {
+		writeError(w, r, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	writeFactors(w, r, factors)
+}
+
+// Return the eigenvalues and eigenvectors of the matrix, complex ones in conjugate pairs
+func EigenHandler(w http.ResponseWriter, r *http.Request) {
+	in, ok := readInput(w, r)
+	if !ok {
+		return
+	}
+	m, err := in.eliminationMatrix()
+	if err != nil {
+		writeError(w, r, err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	}
+	tolerance, maxIterations, err := eigenOptions(r, m.Size())
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, r, err.Error(), http.StatusBadRequest)
 		return
 	}
-	
-	// 2nd Step: validate input matrix
-	err = ValidateSquareMatrix(records)
+	e, err := m.Eigen(tolerance, maxIterations)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, r, err.Error(), http.StatusUnprocessableEntity)
 		return
 	}
 
-	// 3rd Step: calculate product using big.Int and return the product
-	// Use "math/big" package and "int64" to handle large product integer case
-	product := big.NewInt(1)
-	for _, row := range records {
-		for _, val := range row {
-			// no need for error check as we did it already in ValidateSquareMatrix()
-			num, _ := strconv.Atoi(val)
-			product.Mul(product, big.NewInt(int64(num)))
+	writeEigen(w, r, e)
+}
+
+// Kinds of output returned by the matrix endpoints, used to document them in the OpenAPI spec.
+const (
+	outputMatrix     = "matrix"
+	outputList       = "list"
+	outputScalar     = "scalar"
+	outputImage      = "image"
+	outputProperties = "properties"
+	outputRREF       = "rref"
+	outputFactors    = "factors"
+	outputEigen      = "eigen"
+	outputPower      = "power"
+	outputPolynomial = "polynomial"
+)
+
+// route is a matrix endpoint, the rate limit budget it is charged to and how it is documented.
+type route struct {
+	path    string
+	handler http.HandlerFunc
+	cost    costClass
+	summary string
+	output  string
+	// parameters are the query parameters of the output, matrixOutputParameters or
+	// valueOutputParameters by output kind when nil
+	parameters []map[string]any
+}
+
+// routes lists every matrix endpoint served by NewRouter and described in the OpenAPI spec.
+var routes = []route{
+	{path: "/echo", handler: EchoHandler, cost: costCheap, output: outputMatrix,
+		summary: "Return the matrix as a string in matrix format"},
+	{path: "/invert", handler: InvertHandler, cost: costCheap, output: outputMatrix,
+		summary: "Return the matrix with the columns and rows inverted"},
+	{path: "/flatten", handler: FlattenHandler, cost: costCheap, output: outputList,
+		summary: "Return the matrix as a 1 line string, with values separated by commas"},
+	{path: "/sum", handler: SumHandler, cost: costCheap, output: outputScalar, parameters: modularParameters,
+		summary: "Return the sum of the integers in the matrix"},
+	{path: "/multiply", handler: MultiplyHandler, cost: costExpensive, output: outputScalar, parameters: modularParameters,
+		summary: "Return the product of the integers in the matrix"},
+	{path: "/render", handler: RenderHandler, cost: costExpensive, output: outputImage, parameters: heatmapParameters,
+		summary: "Return a heatmap image of the matrix as PNG or SVG"},
+	{path: "/properties", handler: PropertiesHandler, cost: costExpensive, output: outputProperties, parameters: []map[string]any{modParameter},
+		summary: "Return the trace, rank, determinant and structural properties of the matrix as JSON"},
+	{path: "/rref", handler: RREFHandler, cost: costExpensive, output: outputRREF, parameters: rrefParameters,
+		summary: "Return the reduced row echelon form of the matrix with exact fractions, and optionally the row operations"},
+	{path: "/power", handler: PowerHandler, cost: costExpensive, output: outputPower, parameters: powerParameters,
+		summary: "Return the matrix to the power n, computed by repeated squaring"},
+	{path: "/charpoly", handler: CharPolyHandler, cost: costExpensive, output: outputPolynomial, parameters: polynomialParameters,
+		summary: "Return the exact characteristic polynomial of the matrix, and optionally its minimal polynomial"},
+	{path: "/decompose/lu", handler: LUHandler, cost: costExpensive, output: outputFactors, parameters: factorsParameters,
+		summary: "Return the LU decomposition of the matrix with partial pivoting, P*A = L*U"},
+	{path: "/decompose/qr", handler: QRHandler, cost: costExpensive, output: outputFactors, parameters: factorsParameters,
+		summary: "Return the QR decomposition of the matrix, A = Q*R"},
+	{path: "/decompose/cholesky", handler: CholeskyHandler, cost: costExpensive, output: outputFactors, parameters: factorsParameters,
+		summary: "Return the Cholesky decomposition of a symmetric positive-definite matrix, A = L*Lᵀ"},
+	{path: "/eigen", handler: EigenHandler, cost: costExpensive, output: outputEigen, parameters: eigenParameters,
+		summary: "Return the eigenvalues and eigenvectors of the matrix, complex ones in conjugate pairs"},
+}
+
+// NewRouter registers the upload page and all matrix endpoints for POST requests behind the
+// rate limiter, and behind API key authentication when keys are configured, and wraps them with
+// the request logging, panic recovery, CORS and compression middleware. Semicolons in the query
+// string are kept as data for the matrix query parameter.
+func NewRouter(cfg Config, logger *slog.Logger) http.Handler {
+	limiter := NewRateLimiter(cfg.RateLimits)
+	var keys *KeyStore
+	if len(cfg.APIKeys) > 0 {
+		keys = NewKeyStore(cfg.APIKeys, cfg.QuotaWindow)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", IndexHandler)
+	mux.Handle("GET /openapi.json", OpenAPIHandler(cfg))
+	for _, rt := range routes {
+		// Authenticate first so that rate limits apply per API key
+		handler := limiter.Limit(rt.cost, rt.handler)
+		if keys != nil {
+			handler = keys.Middleware(handler)
+		}
+		mux.Handle("POST "+rt.path, handler)
+	}
+	if cfg.DebugVars {
+		// The metrics expose the command line of the process, so they need a key when keys are set
+		var vars http.Handler = expvar.Handler()
+		if keys != nil {
+			vars = keys.Middleware(vars)
 		}
+		mux.Handle("GET /debug/vars", vars)
 	}
 
-	fmt.Fprintln(w, product.String())
+	handler := LoggingMiddleware(logger, RecoveryMiddleware(logger, CORSMiddleware(cfg.CORSOrigins, CompressionMiddleware(mux))))
+	return LiteralSemicolonsMiddleware(handler)
 }
 
 func main() {
-	http.HandleFunc("/echo", EchoHandler)
-	http.HandleFunc("/invert", InvertHandler)
-	http.HandleFunc("/flatten", FlattenHandler)
-	http.HandleFunc("/sum", SumHandler)
-	http.HandleFunc("/multiply", MultiplyHandler)
-
-	port := ":8080"
-	fmt.Println("Server started on port", port)
-	if err := http.ListenAndServe(port, nil); err != nil {
-		fmt.Println("Server failed:", err)
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		logger.Error("invalid configuration", "error", err)
+		os.Exit(1)
+	}
+
+	logger.Info("server started", "port", cfg.Port, "api_keys", len(cfg.APIKeys))
+	if err := http.ListenAndServe(cfg.Port, NewRouter(cfg, logger)); err != nil {
+		logger.Error("server failed", "error", err)
+		os.Exit(1)
 	}
 }
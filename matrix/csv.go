@@ -0,0 +1,270 @@
+package matrix
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Parser reads and validates matrices. The zero value reads strict comma separated values,
+// the same as the package level functions.
+type Parser struct {
+	// Delimiter separates the values: 0 for comma, DelimiterAuto to detect it from the input,
+	// DelimiterWhitespace for runs of spaces and tabs, or any other single character
+	Delimiter rune
+	// Lenient normalizes the input before it is validated, see ReadCSVNormalized
+	Lenient bool
+	// Header reads the first row as column labels instead of values
+	Header bool
+	// Labels reads the first column as row labels instead of values
+	Labels bool
+	// BasePrefixes accepts Go integer literals with a base prefix and underscores, such as
+	// 0xFF, 0b1010, 0o17 or 1_000. A leading 0 without a letter stays decimal, so that
+	// zero-padded values such as 007 keep their value.
+	BasePrefixes bool
+}
+
+// ReadCSV reads all records from comma separated input.
+func ReadCSV(r io.Reader) ([][]string, error) {
+	return Parser{}.ReadCSV(r)
+}
+
+// ValidateSquare checks if the records are a square matrix that contains only integers and has no header row.
+func ValidateSquare(records [][]string) error {
+	return Parser{}.ValidateSquare(records)
+}
+
+// FromRecords validates the records with ValidateSquare and returns them as a Matrix.
+func FromRecords(records [][]string) (*Matrix, error) {
+	return Parser{}.FromRecords(records)
+}
+
+// ParseCSV reads comma separated input and returns it as a validated Matrix.
+func ParseCSV(r io.Reader) (*Matrix, error) {
+	return Parser{}.ParseCSV(r)
+}
+
+// ReadCSV reads all records from delimited input.
+func (p Parser) ReadCSV(r io.Reader) ([][]string, error) {
+	records, _, err := p.ReadCSVNormalized(r)
+	return records, err
+}
+
+// ReadCSVNormalized reads all records from delimited input like ReadCSV. In lenient mode it
+// also strips a UTF-8 BOM, converts CRLF line endings, skips # comment lines and blank lines
+// and trims the whitespace around values, and returns the normalizations that changed the input.
+func (p Parser) ReadCSVNormalized(r io.Reader) ([][]string, []Normalization, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse csv: %v", err)
+	}
+
+	var applied normalizations
+	if p.Lenient {
+		data = applied.normalizeText(data)
+	}
+
+	delimiter := p.Delimiter
+	if delimiter == DelimiterAuto {
+		delimiter = DetectDelimiter(data)
+	}
+
+	var records [][]string
+	if delimiter == DelimiterWhitespace {
+		records = readWhitespaceSeparated(data)
+	} else {
+		reader := csv.NewReader(bytes.NewReader(data))
+		if delimiter != 0 {
+			reader.Comma = delimiter
+		}
+		// Disable automatic field count checking to allow different row length and header cases
+		// since we check them in latter ValidateSquare() to return more specific error.
+		reader.FieldsPerRecord = -1
+		records, err = reader.ReadAll()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to parse csv: %v", err)
+		}
+	}
+	if p.Lenient {
+		records = applied.normalizeRecords(records)
+	}
+
+	// If csv file is empty
+	if len(records) == 0 {
+		return nil, nil, fmt.Errorf("failed to parse csv: file is empty")
+	}
+
+	return records, applied.list(), nil
+}
+
+// ValidateSquare checks if the records are a square matrix that contains only integers and has
+// no header row, or only the header row and label column configured with Header and Labels.
+// Errors report the row and column in the records.
+func (p Parser) ValidateSquare(records [][]string) error {
+	_, _, _, err := p.split(records)
+	return err
+}
+
+// FromRecords validates the records with ValidateSquare and returns them as a Matrix.
+func (p Parser) FromRecords(records [][]string) (*Matrix, error) {
+	cells, rowLabels, colLabels, err := p.split(records)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([][]int64, len(cells))
+	for i, row := range cells {
+		values[i] = make([]int64, len(row))
+		for j, val := range row {
+			// no need for error check as we did it already in validateValues()
+			values[i][j], _ = p.parseValue(val)
+		}
+	}
+
+	return &Matrix{Values: values, RowLabels: rowLabels, ColLabels: colLabels}, nil
+}
+
+// split separates the header row and the label column from the values and validates them.
+func (p Parser) split(records [][]string) (cells [][]string, rowLabels []string, colLabels []string, err error) {
+	rowOffset, colOffset := 0, 0
+	if p.Header && len(records) > 0 {
+		colLabels = records[0]
+		records = records[1:]
+		rowOffset = 1
+	}
+	if p.Labels {
+		colOffset = 1
+		if colLabels != nil {
+			colLabels = colLabels[min(1, len(colLabels)):]
+		}
+		cells = make([][]string, len(records))
+		rowLabels = make([]string, len(records))
+		for i, row := range records {
+			if len(row) > 0 {
+				rowLabels[i], cells[i] = row[0], row[1:]
+			}
+		}
+	} else {
+		cells = records
+	}
+
+	if err := p.validateValues(cells, rowOffset, colOffset); err != nil {
+		return nil, nil, nil, err
+	}
+	if colLabels != nil && len(colLabels) != len(cells) {
+		return nil, nil, nil, fmt.Errorf("matrix header row has %d labels, expected %d", len(colLabels), len(cells))
+	}
+	return cells, rowLabels, colLabels, nil
+}
+
+// validateValues checks if the values are a square matrix of integers. The offsets are the
+// rows and columns before the values in the records, added to the reported positions.
+func (p Parser) validateValues(records [][]string, rowOffset int, colOffset int) error {
+	// Empty matrix case
+	if len(records) == 0 {
+		return fmt.Errorf("empty matrix")
+	}
+
+	n := len(records[0])
+	// Check first row for header (all values should be integers), unless it was read as the header
+	if !p.Header {
+		for j, val := range records[0] {
+			if _, err := p.parseValue(val); err != nil {
+				return fmt.Errorf("matrix has a header row or non-integer value at row 1, column %d: %v", j+colOffset+1, err)
+			}
+		}
+	}
+
+	for i, row := range records {
+		// Check if each row length is equal to the first row length
+		if len(row) != n {
+			return fmt.Errorf("matrix is not square: row %d has %d columns, expected %d", i+rowOffset+1, len(row)+colOffset, n+colOffset)
+		}
+
+		for j, val := range row {
+			// Check for empty value
+			if val == "" {
+				return fmt.Errorf("matrix has empty value at row %d, column %d", i+rowOffset+1, j+colOffset+1)
+			}
+			// Check for integer
+			if _, err := p.parseValue(val); err != nil {
+				return fmt.Errorf("matrix value at row %d, column %d is not an integer: %v", i+rowOffset+1, j+colOffset+1, err)
+			}
+		}
+	}
+
+	// Check if number of rows equals number of columns
+	if len(records) != n {
+		return fmt.Errorf("matrix is not square: %d rows and %d columns", len(records), n)
+	}
+
+	return nil
+}
+
+// ParseCSV reads delimited input and returns it as a validated Matrix.
+func (p Parser) ParseCSV(r io.Reader) (*Matrix, error) {
+	records, err := p.ReadCSV(r)
+	if err != nil {
+		return nil, err
+	}
+	return p.FromRecords(records)
+}
+
+// decimalNumber matches numbers with a decimal point or a decimal comma, such as "1.5" or "1,5".
+var decimalNumber = regexp.MustCompile(`^[+-]?\d+[.,]\d+$`)
+
+// prefixedLiteral matches integer literals with a base prefix, such as "0xFF" or "-0b1010".
+var prefixedLiteral = regexp.MustCompile(`^[+-]?0[xXbBoO]`)
+
+// parseValue parses a matrix cell as a base 10 integer, or as a Go integer literal with BasePrefixes.
+func (p Parser) parseValue(val string) (int64, error) {
+	if p.BasePrefixes {
+		if prefixedLiteral.MatchString(val) {
+			return strconv.ParseInt(val, 0, 64)
+		}
+		// Without a prefix letter the value is decimal: leading zeros are dropped so that
+		// ParseInt does not read it as octal, and it still checks the underscores
+		sign, digits := "", val
+		if strings.HasPrefix(val, "+") || strings.HasPrefix(val, "-") {
+			sign, digits = val[:1], val[1:]
+		}
+		if trimmed := strings.TrimLeft(digits, "0"); trimmed != digits {
+			digits = trimmed
+			if digits == "" {
+				digits = "0"
+			}
+		}
+		if num, err := strconv.ParseInt(sign+digits, 0, 64); err == nil {
+			return num, nil
+		}
+	}
+
+	num, err := strconv.ParseInt(val, 10, 64)
+	if err != nil && !p.BasePrefixes && prefixedLiteral.MatchString(val) {
+		return 0, fmt.Errorf("%q has a base prefix, enable base prefixes to read it", val)
+	}
+	if err != nil && decimalNumber.MatchString(val) {
+		return 0, fmt.Errorf("%q is a decimal number, only integers are supported", val)
+	}
+	if err != nil && strings.TrimSpace(val) != val && val != "" {
+		if _, trimErr := strconv.ParseInt(strings.TrimSpace(val), 10, 64); trimErr == nil {
+			return 0, fmt.Errorf("%q has surrounding whitespace, use lenient mode to trim it", val)
+		}
+	}
+	return num, err
+}
+
+// readWhitespaceSeparated splits every non-blank line of the input on runs of spaces and tabs.
+func readWhitespaceSeparated(data []byte) [][]string {
+	var records [][]string
+	for _, line := range strings.Split(string(data), "\n") {
+		if fields := strings.Fields(line); len(fields) > 0 {
+			records = append(records, fields)
+		}
+	}
+	return records
+}
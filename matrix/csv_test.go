@@ -0,0 +1,322 @@
+package matrix
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type CSVTestSuite struct {
+	suite.Suite
+}
+
+// Test for ReadCSV
+func (s *CSVTestSuite) TestReadCSV() {
+	tests := []struct {
+		name        string
+		csvContent  string
+		expectErr   bool
+		errorSubstr string
+		expectRows  int
+		expectCols  int
+	}{
+		{
+			name:       "valid csv",
+			csvContent: "1,2,3\n4,5,6\n7,8,9",
+			expectErr:  false,
+			expectRows: 3,
+			expectCols: 3,
+		},
+		{
+			name:       "rows with different length are kept",
+			csvContent: "1,2,3\n4,5",
+			expectErr:  false,
+			expectRows: 2,
+			expectCols: 3,
+		},
+		{
+			name:        "invalid csv format",
+			csvContent:  "\"1,2,3\n4,5,6",
+			expectErr:   true,
+			errorSubstr: "failed to parse csv",
+		},
+		{
+			name:        "empty input",
+			csvContent:  "",
+			expectErr:   true,
+			errorSubstr: "failed to parse csv: file is empty",
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			records, err := ReadCSV(strings.NewReader(tc.csvContent))
+
+			if tc.expectErr {
+				s.Error(err)
+				s.Contains(err.Error(), tc.errorSubstr)
+			} else {
+				s.NoError(err)
+				s.Len(records, tc.expectRows)
+				s.Len(records[0], tc.expectCols)
+			}
+		})
+	}
+}
+
+// Test for ValidateSquare
+func (s *CSVTestSuite) TestValidateSquare() {
+	tests := []struct {
+		name        string
+		matrix      [][]string
+		expectErr   bool
+		errorSubstr string
+	}{
+		{
+			name: "valid square 2*2 matrix",
+			matrix: [][]string{
+				{"1", "2"},
+				{"3", "4"},
+			},
+			expectErr: false,
+		},
+		{
+			name: "valid square 3*3 matrix",
+			matrix: [][]string{
+				{"1", "2", "3"},
+				{"4", "5", "6"},
+				{"7", "8", "9"},
+			},
+			expectErr: false,
+		},
+		{
+			name: "valid square 5*5 matrix",
+			matrix: [][]string{
+				{"1", "2", "3", "4", "5"},
+				{"6", "7", "8", "9", "10"},
+				{"11", "12", "13", "14", "15"},
+				{"16", "17", "18", "19", "20"},
+				{"21", "22", "23", "24", "25"},
+			},
+			expectErr: false,
+		},
+		{
+			name:        "empty matrix",
+			matrix:      [][]string{},
+			expectErr:   true,
+			errorSubstr: "empty matrix",
+		},
+		{
+			name: "matrix has header row",
+			matrix: [][]string{
+				{"This is header", "row content"},
+				{"1", "2"},
+			},
+			expectErr:   true,
+			errorSubstr: "header row",
+		},
+		{
+			name: "matrix has different row length",
+			matrix: [][]string{
+				{"1", "2", "3"},
+				{"4", "5"},
+			},
+			expectErr:   true,
+			errorSubstr: "matrix is not square",
+		},
+		{
+			name: "matrix has empty value",
+			matrix: [][]string{
+				{"1", "2", "3"},
+				{"4", "5", "6"},
+				{"7", "8", ""},
+			},
+			expectErr:   true,
+			errorSubstr: "matrix has empty value",
+		},
+		{
+			name: "matrix has non-integer value",
+			matrix: [][]string{
+				{"1", "2", "3"},
+				{"4", "A", "6"},
+				{"7", "8", "9"},
+			},
+			expectErr:   true,
+			errorSubstr: "is not an integer",
+		},
+		{
+			name: "matrix has more rows than columns",
+			matrix: [][]string{
+				{"1", "2"},
+				{"3", "4"},
+				{"5", "6"},
+			},
+			expectErr:   true,
+			errorSubstr: "matrix is not square",
+		},
+		{
+			name: "matrix has more columns than rows",
+			matrix: [][]string{
+				{"1", "2", "3"},
+				{"4", "5", "6"},
+			},
+			expectErr:   true,
+			errorSubstr: "matrix is not square",
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			err := ValidateSquare(tc.matrix)
+
+			// Check if error contain errorSubstr
+			if tc.expectErr {
+				s.Error(err)
+				if tc.errorSubstr != "" {
+					s.Contains(strings.ToLower(err.Error()), strings.ToLower(tc.errorSubstr))
+				}
+			} else {
+				s.NoError(err)
+			}
+		})
+	}
+}
+
+// Test for ParseCSV
+func (s *CSVTestSuite) TestParseCSV() {
+	m, err := ParseCSV(strings.NewReader("1,2,3\n2,2,-1\n-10,3,5"))
+	s.NoError(err)
+	s.Equal([][]int64{{1, 2, 3}, {2, 2, -1}, {-10, 3, 5}}, m.Values)
+
+	_, err = ParseCSV(strings.NewReader("1,2\n3,4\n5,6"))
+	s.EqualError(err, "matrix is not square: 3 rows and 2 columns")
+}
+
+// Test for the Header and Labels options
+func (s *CSVTestSuite) TestLabels() {
+	tests := []struct {
+		name              string
+		parser            Parser
+		csvContent        string
+		expectedValues    [][]int64
+		expectedRowLabels []string
+		expectedColLabels []string
+		errorSubstr       string
+	}{
+		{
+			name:              "header row",
+			parser:            Parser{Header: true},
+			csvContent:        "a,b\n1,2\n3,4",
+			expectedValues:    [][]int64{{1, 2}, {3, 4}},
+			expectedColLabels: []string{"a", "b"},
+		},
+		{
+			name:              "label column",
+			parser:            Parser{Labels: true},
+			csvContent:        "x,1,2\ny,3,4",
+			expectedValues:    [][]int64{{1, 2}, {3, 4}},
+			expectedRowLabels: []string{"x", "y"},
+		},
+		{
+			name:              "header row and label column",
+			parser:            Parser{Header: true, Labels: true},
+			csvContent:        "city,a,b\nx,1,2\ny,3,4",
+			expectedValues:    [][]int64{{1, 2}, {3, 4}},
+			expectedRowLabels: []string{"x", "y"},
+			expectedColLabels: []string{"a", "b"},
+		},
+		{
+			name:        "header row without header option",
+			parser:      Parser{},
+			csvContent:  "a,b\n1,2\n3,4",
+			errorSubstr: "matrix has a header row or non-integer value at row 1, column 1",
+		},
+		{
+			name:        "positions count the header row and label column",
+			parser:      Parser{Header: true, Labels: true},
+			csvContent:  "city,a,b\nx,1,2\ny,3,z",
+			errorSubstr: "matrix value at row 3, column 3 is not an integer",
+		},
+		{
+			name:        "row length counts the label column",
+			parser:      Parser{Labels: true},
+			csvContent:  "x,1,2\ny,3",
+			errorSubstr: "matrix is not square: row 2 has 2 columns, expected 3",
+		},
+		{
+			name:        "header row with too few labels",
+			parser:      Parser{Header: true},
+			csvContent:  "a\n1,2\n3,4",
+			errorSubstr: "matrix header row has 1 labels, expected 2",
+		},
+		{
+			name:        "only a header row",
+			parser:      Parser{Header: true},
+			csvContent:  "a,b",
+			errorSubstr: "empty matrix",
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			m, err := tc.parser.ParseCSV(strings.NewReader(tc.csvContent))
+			if tc.errorSubstr != "" {
+				s.ErrorContains(err, tc.errorSubstr)
+				return
+			}
+			s.Require().NoError(err)
+			s.Equal(tc.expectedValues, m.Values)
+			s.Equal(tc.expectedRowLabels, m.RowLabels)
+			s.Equal(tc.expectedColLabels, m.ColLabels)
+
+			// Transpose swaps the labels
+			transposed := m.Transpose()
+			s.Equal(tc.expectedColLabels, transposed.RowLabels)
+			s.Equal(tc.expectedRowLabels, transposed.ColLabels)
+		})
+	}
+}
+
+// Test for the BasePrefixes option
+func (s *CSVTestSuite) TestBasePrefixes() {
+	tests := []struct {
+		name          string
+		parser        Parser
+		val           string
+		expectedValue int64
+		errorSubstr   string
+	}{
+		{name: "hexadecimal", parser: Parser{BasePrefixes: true}, val: "0xFF", expectedValue: 255},
+		{name: "binary", parser: Parser{BasePrefixes: true}, val: "0b1010", expectedValue: 10},
+		{name: "octal", parser: Parser{BasePrefixes: true}, val: "0o17", expectedValue: 15},
+		{name: "negative hexadecimal", parser: Parser{BasePrefixes: true}, val: "-0x10", expectedValue: -16},
+		{name: "underscores", parser: Parser{BasePrefixes: true}, val: "1_000_000", expectedValue: 1000000},
+		{name: "zero-padded stays decimal", parser: Parser{BasePrefixes: true}, val: "-010", expectedValue: -10},
+		{name: "zero", parser: Parser{BasePrefixes: true}, val: "00", expectedValue: 0},
+		{name: "malformed hexadecimal", parser: Parser{BasePrefixes: true}, val: "0xZZ", errorSubstr: "parsing \"0xZZ\": invalid syntax"},
+		{name: "misplaced underscore", parser: Parser{BasePrefixes: true}, val: "_1", errorSubstr: "parsing \"_1\": invalid syntax"},
+		{name: "prefix without the option", parser: Parser{}, val: "0xFF", errorSubstr: "\"0xFF\" has a base prefix, enable base prefixes to read it"},
+		{name: "underscores without the option", parser: Parser{}, val: "1_000", errorSubstr: "invalid syntax"},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			num, err := tc.parser.parseValue(tc.val)
+			if tc.errorSubstr != "" {
+				s.ErrorContains(err, tc.errorSubstr)
+				return
+			}
+			s.NoError(err)
+			s.Equal(tc.expectedValue, num)
+		})
+	}
+
+	_, err := Parser{BasePrefixes: true}.ParseCSV(strings.NewReader("0x1,0x2\n0x3,0xG"))
+	s.ErrorContains(err, "matrix value at row 2, column 2 is not an integer")
+}
+
+// Run all tests
+func TestCSVTestSuite(t *testing.T) {
+	suite.Run(t, new(CSVTestSuite))
+}
@@ -0,0 +1,220 @@
+package matrix
+
+import (
+	"fmt"
+	"math"
+)
+
+// Factor is a named matrix of a decomposition, such as the L of an LU decomposition.
+type Factor struct {
+	Name   string
+	Values [][]float64
+}
+
+// LU returns the LU decomposition with partial pivoting P*A = L*U, where P is a permutation
+// matrix, L is lower triangular with ones on its diagonal and U is upper triangular. Singular
+// matrices have a zero on the diagonal of U.
+func (m *Matrix) LU() []Factor {
+	n := m.Size()
+	u := m.floatValues()
+	l := identity(n)
+	perm := make([]int, n)
+	for i := range perm {
+		perm[i] = i
+	}
+
+	for col := range n {
+		// 1st Step: pivot on the largest value of the column for stability
+		pivot := col
+		for i := col + 1; i < n; i++ {
+			if math.Abs(u[i][col]) > math.Abs(u[pivot][col]) {
+				pivot = i
+			}
+		}
+		if pivot != col {
+			u[pivot], u[col] = u[col], u[pivot]
+			perm[pivot], perm[col] = perm[col], perm[pivot]
+			for j := range col {
+				l[pivot][j], l[col][j] = l[col][j], l[pivot][j]
+			}
+		}
+		if u[col][col] == 0 {
+			continue
+		}
+
+		// 2nd Step: eliminate the column below the pivot and keep the multipliers in L
+		for i := col + 1; i < n; i++ {
+			factor := u[i][col] / u[col][col]
+			l[i][col] = factor
+			for j := col; j < n; j++ {
+				u[i][j] -= factor * u[col][j]
+			}
+			u[i][col] = 0
+		}
+	}
+
+	p := zeros(n)
+	for i, j := range perm {
+		p[i][j] = 1
+	}
+	return []Factor{{"P", p}, {"L", cleanZeros(l)}, {"U", cleanZeros(u)}}
+}
+
+// QR returns the QR decomposition A = Q*R computed with Householder reflections, where Q is
+// orthogonal and R is upper triangular with a non-negative diagonal.
+func (m *Matrix) QR() []Factor {
+	n := m.Size()
+	r := m.floatValues()
+	q := identity(n)
+
+	for col := range n - 1 {
+		// 1st Step: build the reflection v that zeroes the column below the diagonal
+		norm := 0.0
+		for i := col; i < n; i++ {
+			norm = math.Hypot(norm, r[i][col])
+		}
+		if norm == 0 {
+			continue
+		}
+		v := make([]float64, n)
+		copy(v[col:], column(r, col)[col:])
+		if v[col] > 0 {
+			v[col] += norm
+		} else {
+			v[col] -= norm
+		}
+		vv := 0.0
+		for _, x := range v[col:] {
+			vv += x * x
+		}
+
+		// 2nd Step: apply it to R from the left and accumulate it in Q from the right
+		for j := range n {
+			dot := 0.0
+			for i := col; i < n; i++ {
+				dot += v[i] * r[i][j]
+			}
+			for i := col; i < n; i++ {
+				r[i][j] -= 2 * dot / vv * v[i]
+			}
+		}
+		for i := range n {
+			dot := 0.0
+			for k := col; k < n; k++ {
+				dot += q[i][k] * v[k]
+			}
+			for k := col; k < n; k++ {
+				q[i][k] -= 2 * dot / vv * v[k]
+			}
+		}
+		for i := col + 1; i < n; i++ {
+			r[i][col] = 0
+		}
+	}
+
+	// Flip the signs of the rows of R with a negative diagonal, and of the columns of Q with
+	// them, so that the decomposition is unique for invertible matrices
+	for i := range n {
+		if r[i][i] >= 0 {
+			continue
+		}
+		for j := range n {
+			r[i][j] = -r[i][j]
+			q[j][i] = -q[j][i]
+		}
+	}
+	return []Factor{{"Q", cleanZeros(q)}, {"R", cleanZeros(r)}}
+}
+
+// Cholesky returns the Cholesky decomposition A = L*Lᵀ, where L is lower triangular with a
+// positive diagonal, or an error if the matrix is not symmetric positive-definite.
+func (m *Matrix) Cholesky() ([]Factor, error) {
+	n := m.Size()
+	for i := range n {
+		for j := i + 1; j < n; j++ {
+			if m.Values[i][j] != m.Values[j][i] {
+				return nil, fmt.Errorf("matrix is not symmetric: value at row %d, column %d is %d but value at row %d, column %d is %d",
+					i+1, j+1, m.Values[i][j], j+1, i+1, m.Values[j][i])
+			}
+		}
+	}
+
+	a := m.floatValues()
+	l := zeros(n)
+	for j := range n {
+		d := a[j][j]
+		for k := range j {
+			d -= l[j][k] * l[j][k]
+		}
+		if d <= 0 {
+			return nil, fmt.Errorf("matrix is not positive-definite: leading minor of order %d is not positive", j+1)
+		}
+		l[j][j] = math.Sqrt(d)
+		for i := j + 1; i < n; i++ {
+			s := a[i][j]
+			for k := range j {
+				s -= l[i][k] * l[j][k]
+			}
+			l[i][j] = s / l[j][j]
+		}
+	}
+	return []Factor{{"L", l}}, nil
+}
+
+// floatValues returns a copy of the values as floats.
+func (m *Matrix) floatValues() [][]float64 {
+	values := make([][]float64, len(m.Values))
+	for i, row := range m.Values {
+		values[i] = make([]float64, len(row))
+		for j, num := range row {
+			values[i][j] = float64(num)
+		}
+	}
+	return values
+}
+
+// zeros returns an n*n matrix of zeros.
+func zeros(n int) [][]float64 {
+	values := make([][]float64, n)
+	for i := range values {
+		values[i] = make([]float64, n)
+	}
+	return values
+}
+
+// identity returns the n*n identity matrix.
+func identity(n int) [][]float64 {
+	values := zeros(n)
+	for i := range values {
+		values[i][i] = 1
+	}
+	return values
+}
+
+// column returns a copy of the column j.
+func column(values [][]float64, j int) []float64 {
+	col := make([]float64, len(values))
+	for i, row := range values {
+		col[i] = row[j]
+	}
+	return col
+}
+
+// cleanZeros replaces the rounding noise around zero, such as 1e-17, and negative zeros
+// with zero.
+func cleanZeros(values [][]float64) [][]float64 {
+	scale := 0.0
+	for _, row := range values {
+		for _, x := range row {
+			scale = max(scale, math.Abs(x))
+		}
+	}
+	for _, row := range values {
+		for j, x := range row {
+			if math.Abs(x) <= 1e-14*scale {
+				row[j] = 0
+			}
+		}
+	}
+	return values
+}
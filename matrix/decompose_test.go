@@ -0,0 +1,125 @@
+package matrix
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type DecomposeTestSuite struct {
+	suite.Suite
+}
+
+// multiply returns the product of the float matrices.
+func multiply(a, b [][]float64) [][]float64 {
+	product := zeros(len(a))
+	for i := range a {
+		for j := range b {
+			for k := range b {
+				product[i][j] += a[i][k] * b[k][j]
+			}
+		}
+	}
+	return product
+}
+
+// transpose returns the transpose of the float matrix.
+func transpose(a [][]float64) [][]float64 {
+	transposed := zeros(len(a))
+	for i, row := range a {
+		for j, x := range row {
+			transposed[j][i] = x
+		}
+	}
+	return transposed
+}
+
+// assertClose checks that the float matrices are equal up to rounding.
+func (s *DecomposeTestSuite) assertClose(expected, actual [][]float64) {
+	s.Require().Len(actual, len(expected))
+	for i := range expected {
+		s.InDeltaSlice(expected[i], actual[i], 1e-9, "row %d", i+1)
+	}
+}
+
+var decomposeMatrices = map[string][][]int64{
+	"1*1":             {{-7}},
+	"2*2 with pivot":  {{0, 2}, {3, 1}},
+	"singular 3*3":    {{1, 2, 3}, {4, 5, 6}, {7, 8, 9}},
+	"4*4":             {{1, 2, 3, 4}, {2, 2, -1, -10}, {3, 3, 5, -2}, {4, 3, 2, 1}},
+	"zero column 3*3": {{0, 1, 2}, {0, 3, 4}, {0, 5, 7}},
+}
+
+// Test that P*A = L*U with a triangular L and U
+func (s *DecomposeTestSuite) TestLU() {
+	for name, values := range decomposeMatrices {
+		s.Run(name, func() {
+			m := &Matrix{Values: values}
+			factors := m.LU()
+			s.Require().Len(factors, 3)
+			s.Equal([]string{"P", "L", "U"}, []string{factors[0].Name, factors[1].Name, factors[2].Name})
+			p, l, u := factors[0].Values, factors[1].Values, factors[2].Values
+
+			s.assertClose(multiply(p, m.floatValues()), multiply(l, u))
+			for i := range l {
+				s.Equal(1.0, l[i][i])
+				for j := i + 1; j < len(l); j++ {
+					s.Zero(l[i][j])
+					s.Zero(u[j][i])
+				}
+			}
+		})
+	}
+
+	factors := (&Matrix{Values: [][]int64{{1, 2}, {3, 4}}}).LU()
+	s.Equal([][]float64{{0, 1}, {1, 0}}, factors[0].Values)
+	s.assertClose([][]float64{{1, 0}, {1.0 / 3, 1}}, factors[1].Values)
+	s.assertClose([][]float64{{3, 4}, {0, 2.0 / 3}}, factors[2].Values)
+}
+
+// Test that A = Q*R with an orthogonal Q and an upper triangular R
+func (s *DecomposeTestSuite) TestQR() {
+	for name, values := range decomposeMatrices {
+		s.Run(name, func() {
+			m := &Matrix{Values: values}
+			factors := m.QR()
+			s.Require().Len(factors, 2)
+			q, r := factors[0].Values, factors[1].Values
+
+			s.assertClose(m.floatValues(), multiply(q, r))
+			s.assertClose(identity(len(q)), multiply(transpose(q), q))
+			for i := range r {
+				s.GreaterOrEqual(r[i][i], 0.0)
+				for j := range i {
+					s.Zero(r[i][j])
+				}
+			}
+		})
+	}
+
+	factors := (&Matrix{Values: [][]int64{{3, 0}, {4, 5}}}).QR()
+	s.assertClose([][]float64{{0.6, -0.8}, {0.8, 0.6}}, factors[0].Values)
+	s.assertClose([][]float64{{5, 4}, {0, 3}}, factors[1].Values)
+}
+
+// Test for Cholesky
+func (s *DecomposeTestSuite) TestCholesky() {
+	factors, err := (&Matrix{Values: [][]int64{{4, 12, -16}, {12, 37, -43}, {-16, -43, 98}}}).Cholesky()
+	s.Require().NoError(err)
+	s.Equal("L", factors[0].Name)
+	s.assertClose([][]float64{{2, 0, 0}, {6, 1, 0}, {-8, 5, 3}}, factors[0].Values)
+
+	_, err = (&Matrix{Values: [][]int64{{1, 2}, {3, 4}}}).Cholesky()
+	s.EqualError(err, "matrix is not symmetric: value at row 1, column 2 is 2 but value at row 2, column 1 is 3")
+
+	_, err = (&Matrix{Values: [][]int64{{1, 2}, {2, 1}}}).Cholesky()
+	s.EqualError(err, "matrix is not positive-definite: leading minor of order 2 is not positive")
+
+	_, err = (&Matrix{Values: [][]int64{{0}}}).Cholesky()
+	s.EqualError(err, "matrix is not positive-definite: leading minor of order 1 is not positive")
+}
+
+// Run all tests
+func TestDecomposeTestSuite(t *testing.T) {
+	suite.Run(t, new(DecomposeTestSuite))
+}
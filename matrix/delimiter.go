@@ -0,0 +1,89 @@
+package matrix
+
+import (
+	"bytes"
+	"fmt"
+	"unicode/utf8"
+)
+
+const (
+	// DelimiterAuto detects the delimiter from the input with DetectDelimiter
+	DelimiterAuto rune = -1
+	// DelimiterWhitespace separates values by runs of spaces and tabs
+	DelimiterWhitespace rune = ' '
+)
+
+// delimiterNames are the names accepted by ParseDelimiter besides single characters.
+var delimiterNames = map[string]rune{
+	"auto":       DelimiterAuto,
+	"comma":      ',',
+	"tab":        '\t',
+	"semicolon":  ';',
+	"pipe":       '|',
+	"whitespace": DelimiterWhitespace,
+	"space":      DelimiterWhitespace,
+}
+
+// detectedDelimiters are the candidates of DetectDelimiter, by preference. A semicolon
+// file may have decimal commas, so the comma is tried last.
+var detectedDelimiters = []rune{'\t', ';', '|', ','}
+
+// ParseDelimiter returns the delimiter with the given name, such as "semicolon", or the
+// single character itself, such as ";".
+func ParseDelimiter(name string) (rune, error) {
+	if delimiter, ok := delimiterNames[name]; ok {
+		return delimiter, nil
+	}
+	if delimiter, size := utf8.DecodeRuneInString(name); size == len(name) && validDelimiter(delimiter) {
+		return delimiter, nil
+	}
+	return 0, fmt.Errorf("invalid delimiter %q: use auto, comma, tab, semicolon, pipe, whitespace or a single character", name)
+}
+
+// validDelimiter reports whether the character can separate values.
+func validDelimiter(r rune) bool {
+	return r != utf8.RuneError && r != '"' && r != '\r' && r != '\n' && r != '-' && r != '+' && (r < '0' || r > '9')
+}
+
+// DetectDelimiter guesses the delimiter of the input from its first lines. A candidate that
+// occurs the same number of times on every line wins, then the most frequent one. Input
+// without any candidate is whitespace separated if it has spaces or tabs, else comma separated.
+func DetectDelimiter(data []byte) rune {
+	var lines [][]byte
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			lines = append(lines, line)
+		}
+		if len(lines) == 20 {
+			break
+		}
+	}
+
+	best, bestCount := rune(0), 0
+	for _, delimiter := range detectedDelimiters {
+		consistent, total := true, 0
+		for _, line := range lines {
+			count := bytes.Count(line, []byte(string(delimiter)))
+			if count == 0 || count != bytes.Count(lines[0], []byte(string(delimiter))) {
+				consistent = false
+			}
+			total += count
+		}
+		if consistent && total > 0 {
+			return delimiter
+		}
+		if total > bestCount {
+			best, bestCount = delimiter, total
+		}
+	}
+
+	if best != 0 {
+		return best
+	}
+	for _, line := range lines {
+		if bytes.ContainsAny(line, " \t") {
+			return DelimiterWhitespace
+		}
+	}
+	return ','
+}
@@ -0,0 +1,120 @@
+package matrix
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type DelimiterTestSuite struct {
+	suite.Suite
+}
+
+// Test for ParseDelimiter
+func (s *DelimiterTestSuite) TestParseDelimiter() {
+	tests := []struct {
+		name      string
+		expected  rune
+		expectErr bool
+	}{
+		{name: "auto", expected: DelimiterAuto},
+		{name: "semicolon", expected: ';'},
+		{name: "tab", expected: '\t'},
+		{name: "whitespace", expected: DelimiterWhitespace},
+		{name: ";", expected: ';'},
+		{name: "|", expected: '|'},
+		{name: "colon", expectErr: true},
+		{name: "\"", expectErr: true},
+		{name: "5", expectErr: true},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			delimiter, err := ParseDelimiter(tc.name)
+			if tc.expectErr {
+				s.ErrorContains(err, "invalid delimiter")
+			} else {
+				s.NoError(err)
+				s.Equal(tc.expected, delimiter)
+			}
+		})
+	}
+}
+
+// Test for DetectDelimiter
+func (s *DelimiterTestSuite) TestDetectDelimiter() {
+	tests := []struct {
+		name     string
+		input    string
+		expected rune
+	}{
+		{name: "comma", input: "1,2,3\n4,5,6\n7,8,9", expected: ','},
+		{name: "tab", input: "1\t2\n3\t4\n", expected: '\t'},
+		{name: "semicolon with decimal commas", input: "1,5;2\n3;4,25\n", expected: ';'},
+		{name: "pipe", input: "1|2\r\n3|4\r\n", expected: '|'},
+		{name: "whitespace", input: "1  2\n 3 4\n", expected: DelimiterWhitespace},
+		{name: "comma with different row length", input: "1,2,3\n4,5\n6,7,8", expected: ','},
+		{name: "comma with header row", input: "This is a header\n1,2,3\n4,5,6", expected: ','},
+		{name: "single value", input: "5", expected: ','},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			s.Equal(tc.expected, DetectDelimiter([]byte(tc.input)))
+		})
+	}
+}
+
+// Test for ParseCSV with a delimiter
+func (s *DelimiterTestSuite) TestParseWithDelimiter() {
+	tests := []struct {
+		name        string
+		delimiter   rune
+		input       string
+		expected    [][]int64
+		errorSubstr string
+	}{
+		{
+			name:      "auto detected semicolon",
+			delimiter: DelimiterAuto,
+			input:     "1;2\n3;4\n",
+			expected:  [][]int64{{1, 2}, {3, 4}},
+		},
+		{
+			name:      "whitespace",
+			delimiter: DelimiterWhitespace,
+			input:     "1 \t 2\n\n-3   4\n",
+			expected:  [][]int64{{1, 2}, {-3, 4}},
+		},
+		{
+			name:        "decimal comma is reported at its cell",
+			delimiter:   ';',
+			input:       "1;2\n3;4,5\n",
+			errorSubstr: "matrix value at row 2, column 2 is not an integer: \"4,5\" is a decimal number",
+		},
+		{
+			name:        "wrong delimiter",
+			delimiter:   ';',
+			input:       "1,2\n3,4\n",
+			errorSubstr: "matrix has a header row or non-integer value at row 1, column 1",
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			m, err := Parser{Delimiter: tc.delimiter}.ParseCSV(strings.NewReader(tc.input))
+			if tc.errorSubstr != "" {
+				s.ErrorContains(err, tc.errorSubstr)
+			} else {
+				s.NoError(err)
+				s.Equal(tc.expected, m.Values)
+			}
+		})
+	}
+}
+
+// Run all tests
+func TestDelimiterTestSuite(t *testing.T) {
+	suite.Run(t, new(DelimiterTestSuite))
+}
@@ -0,0 +1,601 @@
+package matrix
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"math/cmplx"
+	"slices"
+)
+
+// DefaultEigenTolerance is the relative size below which a subdiagonal value is taken as zero
+// by Eigen, the machine epsilon of float64.
+const DefaultEigenTolerance = 0x1p-52
+
+// Eigen is the eigendecomposition of a real matrix.
+type Eigen struct {
+	// Values are the eigenvalues by decreasing real part then imaginary part, complex ones in
+	// conjugate pairs
+	Values []complex128
+	// Vectors are the eigenvectors of the values, with unit length and their largest
+	// component real and positive. Defective matrices, such as [[1, 1], [0, 1]], have fewer
+	// independent eigenvectors than eigenvalues, so some of their vectors are repeated
+	Vectors [][]complex128
+	// Iterations is the number of shifted QR steps taken
+	Iterations int
+}
+
+// SpectralRadius returns the largest absolute value of the eigenvalues.
+func (e *Eigen) SpectralRadius() float64 {
+	radius := 0.0
+	for _, v := range e.Values {
+		radius = max(radius, cmplx.Abs(v))
+	}
+	return radius
+}
+
+// Eigen returns the eigenvalues and eigenvectors of the matrix. The matrix is reduced to upper
+// Hessenberg form with Householder reflections, then to real Schur form with Francis double
+// shifted QR steps, from which the eigenvectors are found by back substitution. This is the
+// hqr2 algorithm of EISPACK as ported by the JAMA library.
+//
+// Subdiagonal values smaller than tolerance times their neighbors are taken as zero. An error
+// is returned when the QR steps have not converged after maxIterations.
+func (m *Matrix) Eigen(tolerance float64, maxIterations int) (*Eigen, error) {
+	n := m.Size()
+	h := m.floatValues()
+	v := orthes(h)
+	d, e, iterations, err := hqr2(h, v, tolerance, maxIterations)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Eigen{Iterations: iterations}
+	for j := 0; j < n; j++ {
+		vector := make([]complex128, n)
+		switch {
+		case e[j] > 0:
+			// The columns j and j+1 are the real and imaginary parts of the vector of d[j]+e[j]i,
+			// and its conjugate is the vector of d[j+1]+e[j+1]i
+			for i := range n {
+				vector[i] = complex(v[i][j], v[i][j+1])
+			}
+			conjugate := make([]complex128, n)
+			for i := range n {
+				conjugate[i] = cmplx.Conj(vector[i])
+			}
+			result.Values = append(result.Values, complex(d[j], e[j]), complex(d[j+1], e[j+1]))
+			result.Vectors = append(result.Vectors, normalize(vector), normalize(conjugate))
+			j++
+		default:
+			for i := range n {
+				vector[i] = complex(v[i][j], 0)
+			}
+			result.Values = append(result.Values, complex(d[j], 0))
+			result.Vectors = append(result.Vectors, normalize(vector))
+		}
+	}
+
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		va, vb := result.Values[a], result.Values[b]
+		return cmp.Or(cmp.Compare(real(vb), real(va)), cmp.Compare(imag(vb), imag(va)))
+	})
+	values, vectors := make([]complex128, n), make([][]complex128, n)
+	for i, j := range order {
+		values[i], vectors[i] = result.Values[j], result.Vectors[j]
+	}
+	result.Values, result.Vectors = values, vectors
+	return result, nil
+}
+
+// vectorRoundoff is the size below which the real and imaginary parts of the components of a
+// unit eigenvector are roundoff of the back substitution, and are set to zero.
+const vectorRoundoff = 1e-12
+
+// normalize scales the vector to unit length, with its largest component real and positive, and
+// sets the parts smaller than vectorRoundoff to zero.
+func normalize(vector []complex128) []complex128 {
+	norm, largest := 0.0, complex128(0)
+	for _, x := range vector {
+		norm = math.Hypot(norm, cmplx.Abs(x))
+		if cmplx.Abs(x) > cmplx.Abs(largest) {
+			largest = x
+		}
+	}
+	if norm == 0 {
+		return vector
+	}
+	// Rotating by the conjugate phase of the largest component makes it real and positive
+	scale := cmplx.Conj(largest) / complex(cmplx.Abs(largest)*norm, 0)
+	for i, x := range vector {
+		x *= scale
+		re, im := real(x), imag(x)
+		if math.Abs(re) <= vectorRoundoff {
+			re = 0
+		}
+		if math.Abs(im) <= vectorRoundoff {
+			im = 0
+		}
+		vector[i] = complex(re, im)
+	}
+	return vector
+}
+
+// orthes reduces h to upper Hessenberg form in place with Householder similarity
+// transformations, and returns their accumulated orthogonal matrix.
+func orthes(h [][]float64) [][]float64 {
+	n := len(h)
+	high := n - 1
+	ort := make([]float64, n)
+
+	for m := 1; m <= high-1; m++ {
+		// 1st Step: scale the column
+		scale := 0.0
+		for i := m; i <= high; i++ {
+			scale += math.Abs(h[i][m-1])
+		}
+		if scale == 0 {
+			continue
+		}
+
+		// 2nd Step: compute the Householder transformation
+		hh := 0.0
+		for i := high; i >= m; i-- {
+			ort[i] = h[i][m-1] / scale
+			hh += ort[i] * ort[i]
+		}
+		g := math.Sqrt(hh)
+		if ort[m] > 0 {
+			g = -g
+		}
+		hh -= ort[m] * g
+		ort[m] -= g
+
+		// 3rd Step: apply it from both sides, H = (I-u*u'/h)*H*(I-u*u'/h)
+		for j := m; j < n; j++ {
+			f := 0.0
+			for i := high; i >= m; i-- {
+				f += ort[i] * h[i][j]
+			}
+			f /= hh
+			for i := m; i <= high; i++ {
+				h[i][j] -= f * ort[i]
+			}
+		}
+		for i := 0; i <= high; i++ {
+			f := 0.0
+			for j := high; j >= m; j-- {
+				f += ort[j] * h[i][j]
+			}
+			f /= hh
+			for j := m; j <= high; j++ {
+				h[i][j] -= f * ort[j]
+			}
+		}
+		ort[m] *= scale
+		h[m][m-1] = scale * g
+	}
+
+	// Accumulate the transformations
+	v := identity(n)
+	for m := high - 1; m >= 1; m-- {
+		if h[m][m-1] == 0 {
+			continue
+		}
+		for i := m + 1; i <= high; i++ {
+			ort[i] = h[i][m-1]
+		}
+		for j := m; j <= high; j++ {
+			g := 0.0
+			for i := m; i <= high; i++ {
+				g += ort[i] * v[i][j]
+			}
+			// Double division avoids possible underflow
+			g = (g / ort[m]) / h[m][m-1]
+			for i := m; i <= high; i++ {
+				v[i][j] += g * ort[i]
+			}
+		}
+	}
+	return v
+}
+
+// cdiv returns the complex division (xr+xi*i) / (yr+yi*i).
+func cdiv(xr, xi, yr, yi float64) (float64, float64) {
+	if math.Abs(yr) > math.Abs(yi) {
+		r := yi / yr
+		d := yr + r*yi
+		return (xr + r*xi) / d, (xi - r*xr) / d
+	}
+	r := yr / yi
+	d := yi + r*yr
+	return (r*xr + xi) / d, (r*xi - xr) / d
+}
+
+// hqr2 reduces the Hessenberg matrix h to real Schur form with shifted QR steps and returns the
+// real and imaginary parts of the eigenvalues and the number of steps. The eigenvectors replace
+// the columns of v: a real eigenvalue has a real vector, and a complex pair with e[j] > 0 has
+// the real and imaginary parts of the vector of d[j]+e[j]i in the columns j and j+1.
+func hqr2(h, v [][]float64, tolerance float64, maxIterations int) (d, e []float64, iterations int, err error) {
+	nn := len(h)
+	n := nn - 1
+	low, high := 0, nn-1
+	const eps = DefaultEigenTolerance
+	d, e = make([]float64, nn), make([]float64, nn)
+	exshift := 0.0
+	var p, q, r, s, z, t, w, x, y float64
+
+	// Compute the matrix norm
+	norm := 0.0
+	for i := range nn {
+		for j := max(i-1, 0); j < nn; j++ {
+			norm += math.Abs(h[i][j])
+		}
+	}
+
+	// The zero matrix has only zero eigenvalues, and the subdiagonal values would never be
+	// smaller than tolerance times a zero norm
+	if norm == 0 {
+		return d, e, 0, nil
+	}
+
+	// Outer loop over the eigenvalue index
+	iter := 0
+	for n >= low {
+		// Look for a single small subdiagonal value
+		l := n
+		for l > low {
+			s = math.Abs(h[l-1][l-1]) + math.Abs(h[l][l])
+			if s == 0 {
+				s = norm
+			}
+			if math.Abs(h[l][l-1]) < tolerance*s {
+				break
+			}
+			l--
+		}
+
+		switch {
+		case l == n:
+			// One root found
+			h[n][n] += exshift
+			d[n], e[n] = h[n][n], 0
+			n--
+			iter = 0
+
+		case l == n-1:
+			// Two roots found
+			w = h[n][n-1] * h[n-1][n]
+			p = (h[n-1][n-1] - h[n][n]) / 2
+			q = p*p + w
+			z = math.Sqrt(math.Abs(q))
+			h[n][n] += exshift
+			h[n-1][n-1] += exshift
+			x = h[n][n]
+
+			if q >= 0 {
+				// Real pair
+				if p >= 0 {
+					z = p + z
+				} else {
+					z = p - z
+				}
+				d[n-1] = x + z
+				d[n] = d[n-1]
+				if z != 0 {
+					d[n] = x - w/z
+				}
+				e[n-1], e[n] = 0, 0
+				x = h[n][n-1]
+				s = math.Abs(x) + math.Abs(z)
+				if s == 0 {
+					// The block is already triangular, rotate by zero
+					p, q = 0, 1
+				} else {
+					p = x / s
+					q = z / s
+					r = math.Sqrt(p*p + q*q)
+					p /= r
+					q /= r
+				}
+
+				// Row modification
+				for j := n - 1; j < nn; j++ {
+					z = h[n-1][j]
+					h[n-1][j] = q*z + p*h[n][j]
+					h[n][j] = q*h[n][j] - p*z
+				}
+				// Column modification
+				for i := 0; i <= n; i++ {
+					z = h[i][n-1]
+					h[i][n-1] = q*z + p*h[i][n]
+					h[i][n] = q*h[i][n] - p*z
+				}
+				// Accumulate transformations
+				for i := low; i <= high; i++ {
+					z = v[i][n-1]
+					v[i][n-1] = q*z + p*v[i][n]
+					v[i][n] = q*v[i][n] - p*z
+				}
+			} else {
+				// Complex pair
+				d[n-1], d[n] = x+p, x+p
+				e[n-1], e[n] = z, -z
+			}
+			n -= 2
+			iter = 0
+
+		default:
+			// No convergence yet
+			if iterations >= maxIterations {
+				return nil, nil, iterations, fmt.Errorf("eigenvalues did not converge after %d iterations: raise max_iterations or tolerance", iterations)
+			}
+
+			// Form the shift
+			x = h[n][n]
+			y, w = 0, 0
+			if l < n {
+				y = h[n-1][n-1]
+				w = h[n][n-1] * h[n-1][n]
+			}
+
+			// Wilkinson's original ad hoc shift
+			if iter == 10 {
+				exshift += x
+				for i := low; i <= n; i++ {
+					h[i][i] -= x
+				}
+				s = math.Abs(h[n][n-1]) + math.Abs(h[n-1][n-2])
+				x = 0.75 * s
+				y = x
+				w = -0.4375 * s * s
+			}
+
+			// MATLAB's new ad hoc shift
+			if iter == 30 {
+				s = (y - x) / 2
+				s = s*s + w
+				if s > 0 {
+					s = math.Sqrt(s)
+					if y < x {
+						s = -s
+					}
+					s = x - w/((y-x)/2+s)
+					for i := low; i <= n; i++ {
+						h[i][i] -= s
+					}
+					exshift += s
+					x, y, w = 0.964, 0.964, 0.964
+				}
+			}
+
+			iter++
+			iterations++
+
+			// Look for two consecutive small subdiagonal values
+			m := n - 2
+			for m >= l {
+				z = h[m][m]
+				r = x - z
+				s = y - z
+				p = (r*s-w)/h[m+1][m] + h[m][m+1]
+				q = h[m+1][m+1] - z - r - s
+				r = h[m+2][m+1]
+				s = math.Abs(p) + math.Abs(q) + math.Abs(r)
+				p /= s
+				q /= s
+				r /= s
+				if m == l {
+					break
+				}
+				if math.Abs(h[m][m-1])*(math.Abs(q)+math.Abs(r)) <
+					tolerance*(math.Abs(p)*(math.Abs(h[m-1][m-1])+math.Abs(z)+math.Abs(h[m+1][m+1]))) {
+					break
+				}
+				m--
+			}
+
+			for i := m + 2; i <= n; i++ {
+				h[i][i-2] = 0
+				if i > m+2 {
+					h[i][i-3] = 0
+				}
+			}
+
+			// Double QR step involving rows l:n and columns m:n
+			for k := m; k <= n-1; k++ {
+				notlast := k != n-1
+				if k != m {
+					p = h[k][k-1]
+					q = h[k+1][k-1]
+					r = 0
+					if notlast {
+						r = h[k+2][k-1]
+					}
+					x = math.Abs(p) + math.Abs(q) + math.Abs(r)
+					if x == 0 {
+						continue
+					}
+					p /= x
+					q /= x
+					r /= x
+				}
+
+				s = math.Sqrt(p*p + q*q + r*r)
+				if p < 0 {
+					s = -s
+				}
+				if s == 0 {
+					continue
+				}
+				if k != m {
+					h[k][k-1] = -s * x
+				} else if l != m {
+					h[k][k-1] = -h[k][k-1]
+				}
+				p += s
+				x = p / s
+				y = q / s
+				z = r / s
+				q /= p
+				r /= p
+
+				// Row modification
+				for j := k; j < nn; j++ {
+					p = h[k][j] + q*h[k+1][j]
+					if notlast {
+						p += r * h[k+2][j]
+						h[k+2][j] -= p * z
+					}
+					h[k][j] -= p * x
+					h[k+1][j] -= p * y
+				}
+				// Column modification
+				for i := 0; i <= min(n, k+3); i++ {
+					p = x*h[i][k] + y*h[i][k+1]
+					if notlast {
+						p += z * h[i][k+2]
+						h[i][k+2] -= p * r
+					}
+					h[i][k] -= p
+					h[i][k+1] -= p * q
+				}
+				// Accumulate transformations
+				for i := low; i <= high; i++ {
+					p = x*v[i][k] + y*v[i][k+1]
+					if notlast {
+						p += z * v[i][k+2]
+						v[i][k+2] -= p * r
+					}
+					v[i][k] -= p
+					v[i][k+1] -= p * q
+				}
+			}
+		}
+	}
+
+	// Back substitute to find the vectors of the upper triangular form
+	for n = nn - 1; n >= 0; n-- {
+		p = d[n]
+		q = e[n]
+
+		switch {
+		case q == 0:
+			// Real vector
+			l := n
+			h[n][n] = 1
+			for i := n - 1; i >= 0; i-- {
+				w = h[i][i] - p
+				r = 0
+				for j := l; j <= n; j++ {
+					r += h[i][j] * h[j][n]
+				}
+				if e[i] < 0 {
+					z = w
+					s = r
+					continue
+				}
+				l = i
+				if e[i] == 0 {
+					if w != 0 {
+						h[i][n] = -r / w
+					} else {
+						h[i][n] = -r / (eps * norm)
+					}
+				} else {
+					// Solve real equations
+					x = h[i][i+1]
+					y = h[i+1][i]
+					q = (d[i]-p)*(d[i]-p) + e[i]*e[i]
+					t = (x*s - z*r) / q
+					h[i][n] = t
+					if math.Abs(x) > math.Abs(z) {
+						h[i+1][n] = (-r - w*t) / x
+					} else {
+						h[i+1][n] = (-s - y*t) / z
+					}
+				}
+
+				// Overflow control
+				t = math.Abs(h[i][n])
+				if (eps*t)*t > 1 {
+					for j := i; j <= n; j++ {
+						h[j][n] /= t
+					}
+				}
+			}
+
+		case q < 0:
+			// Complex vector, the last component is imaginary so the matrix is triangular
+			l := n - 1
+			if math.Abs(h[n][n-1]) > math.Abs(h[n-1][n]) {
+				h[n-1][n-1] = q / h[n][n-1]
+				h[n-1][n] = -(h[n][n] - p) / h[n][n-1]
+			} else {
+				h[n-1][n-1], h[n-1][n] = cdiv(0, -h[n-1][n], h[n-1][n-1]-p, q)
+			}
+			h[n][n-1] = 0
+			h[n][n] = 1
+			for i := n - 2; i >= 0; i-- {
+				var ra, sa, vr, vi float64
+				for j := l; j <= n; j++ {
+					ra += h[i][j] * h[j][n-1]
+					sa += h[i][j] * h[j][n]
+				}
+				w = h[i][i] - p
+
+				if e[i] < 0 {
+					z = w
+					r = ra
+					s = sa
+					continue
+				}
+				l = i
+				if e[i] == 0 {
+					h[i][n-1], h[i][n] = cdiv(-ra, -sa, w, q)
+				} else {
+					// Solve complex equations
+					x = h[i][i+1]
+					y = h[i+1][i]
+					vr = (d[i]-p)*(d[i]-p) + e[i]*e[i] - q*q
+					vi = (d[i] - p) * 2 * q
+					if vr == 0 && vi == 0 {
+						vr = eps * norm * (math.Abs(w) + math.Abs(q) + math.Abs(x) + math.Abs(y) + math.Abs(z))
+					}
+					h[i][n-1], h[i][n] = cdiv(x*r-z*ra+q*sa, x*s-z*sa-q*ra, vr, vi)
+					if math.Abs(x) > math.Abs(z)+math.Abs(q) {
+						h[i+1][n-1] = (-ra - w*h[i][n-1] + q*h[i][n]) / x
+						h[i+1][n] = (-sa - w*h[i][n] - q*h[i][n-1]) / x
+					} else {
+						h[i+1][n-1], h[i+1][n] = cdiv(-r-y*h[i][n-1], -s-y*h[i][n], z, q)
+					}
+				}
+
+				// Overflow control
+				t = max(math.Abs(h[i][n-1]), math.Abs(h[i][n]))
+				if (eps*t)*t > 1 {
+					for j := i; j <= n; j++ {
+						h[j][n-1] /= t
+						h[j][n] /= t
+					}
+				}
+			}
+		}
+	}
+
+	// Back transformation to get the eigenvectors of the original matrix
+	for j := nn - 1; j >= low; j-- {
+		for i := low; i <= high; i++ {
+			z = 0
+			for k := low; k <= min(j, high); k++ {
+				z += v[i][k] * h[k][j]
+			}
+			v[i][j] = z
+		}
+	}
+	return d, e, iterations, nil
+}
@@ -0,0 +1,116 @@
+package matrix
+
+import (
+	"math/cmplx"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type EigenTestSuite struct {
+	suite.Suite
+}
+
+// Test that A*v = λ*v for every eigenvalue λ and unit eigenvector v
+func (s *EigenTestSuite) TestEigen() {
+	matrices := map[string][][]int64{
+		"1*1":            {{-7}},
+		"diagonal 3*3":   {{2, 0, 0}, {0, -1, 0}, {0, 0, 5}},
+		"symmetric 3*3":  {{2, -1, 0}, {-1, 2, -1}, {0, -1, 2}},
+		"rotation 2*2":   {{0, -1}, {1, 0}},
+		"singular 3*3":   {{1, 2, 3}, {4, 5, 6}, {7, 8, 9}},
+		"complex 4*4":    {{1, 2, 3, 4}, {2, 2, -1, -10}, {3, 3, 5, -2}, {4, 3, 2, 1}},
+		"cyclic 5*5":     {{0, 1, 0, 0, 0}, {0, 0, 1, 0, 0}, {0, 0, 0, 1, 0}, {0, 0, 0, 0, 1}, {1, 0, 0, 0, 0}},
+		"triangular 3*3": {{1, 4, 6}, {0, 2, 5}, {0, 0, 3}},
+	}
+
+	for name, values := range matrices {
+		s.Run(name, func() {
+			m := &Matrix{Values: values}
+			eigen, err := m.Eigen(DefaultEigenTolerance, 30*m.Size())
+			s.Require().NoError(err)
+			s.Require().Len(eigen.Values, m.Size())
+			s.Require().Len(eigen.Vectors, m.Size())
+
+			for k, value := range eigen.Values {
+				vector := eigen.Vectors[k]
+				norm := 0.0
+				for _, x := range vector {
+					norm += real(x * cmplx.Conj(x))
+				}
+				s.InDelta(1, norm, 1e-9, "norm of vector %d", k+1)
+				for i, row := range values {
+					product := complex128(0)
+					for j, num := range row {
+						product += complex(float64(num), 0) * vector[j]
+					}
+					s.InDelta(0, cmplx.Abs(product-value*vector[i]), 1e-9, "row %d of vector %d", i+1, k+1)
+				}
+				if k > 0 {
+					s.GreaterOrEqual(real(eigen.Values[k-1]), real(value)-1e-12)
+				}
+			}
+		})
+	}
+}
+
+// Test the known eigenvalues and vectors
+func (s *EigenTestSuite) TestKnownValues() {
+	eigen, err := (&Matrix{Values: [][]int64{{0, -1}, {1, 0}}}).Eigen(DefaultEigenTolerance, 60)
+	s.Require().NoError(err)
+	s.Equal([]complex128{1i, -1i}, eigen.Values)
+	s.InDelta(1, eigen.SpectralRadius(), 1e-12)
+	// The largest component is real and positive
+	s.InDelta(0, cmplx.Abs(eigen.Vectors[0][0]-complex(1/1.4142135623730951, 0)), 1e-12)
+	s.InDelta(0, cmplx.Abs(eigen.Vectors[0][1]-complex(0, -1/1.4142135623730951)), 1e-12)
+
+	eigen, err = (&Matrix{Values: [][]int64{{2, 0}, {0, 3}}}).Eigen(DefaultEigenTolerance, 60)
+	s.Require().NoError(err)
+	s.Equal([]complex128{3, 2}, eigen.Values)
+	s.Equal([][]complex128{{0, 1}, {1, 0}}, eigen.Vectors)
+	s.Equal(0, eigen.Iterations)
+	s.Equal(3.0, eigen.SpectralRadius())
+
+	// The zero matrices converge at once, with the identity vectors
+	eigen, err = (&Matrix{Values: [][]int64{{0, 0}, {0, 0}}}).Eigen(DefaultEigenTolerance, 60)
+	s.Require().NoError(err)
+	s.Equal([]complex128{0, 0}, eigen.Values)
+	s.Equal([][]complex128{{1, 0}, {0, 1}}, eigen.Vectors)
+	s.Zero(eigen.SpectralRadius())
+
+	eigen, err = (&Matrix{Values: [][]int64{{0, 0, 0}, {0, 0, 0}, {0, 0, 0}}}).Eigen(DefaultEigenTolerance, 90)
+	s.Require().NoError(err)
+	s.Equal([]complex128{0, 0, 0}, eigen.Values)
+	s.Equal([][]complex128{{1, 0, 0}, {0, 1, 0}, {0, 0, 1}}, eigen.Vectors)
+	s.Zero(eigen.Iterations)
+}
+
+// Test that the vectors of defective matrices are repeated without roundoff noise
+func (s *EigenTestSuite) TestDefective() {
+	eigen, err := (&Matrix{Values: [][]int64{{1, 1}, {0, 1}}}).Eigen(DefaultEigenTolerance, 60)
+	s.Require().NoError(err)
+	s.Equal([]complex128{1, 1}, eigen.Values)
+	s.Equal([][]complex128{{1, 0}, {1, 0}}, eigen.Vectors)
+
+	eigen, err = (&Matrix{Values: [][]int64{{2, 1, 0}, {0, 2, 1}, {0, 0, 2}}}).Eigen(DefaultEigenTolerance, 90)
+	s.Require().NoError(err)
+	s.Equal([][]complex128{{1, 0, 0}, {1, 0, 0}, {1, 0, 0}}, eigen.Vectors)
+}
+
+// Test the convergence failure
+func (s *EigenTestSuite) TestNoConvergence() {
+	m := &Matrix{Values: [][]int64{{1, 2, 3, 4}, {2, 2, -1, -10}, {3, 3, 5, -2}, {4, 3, 2, 1}}}
+	_, err := m.Eigen(DefaultEigenTolerance, 1)
+	s.EqualError(err, "eigenvalues did not converge after 1 iterations: raise max_iterations or tolerance")
+
+	eigen, err := m.Eigen(1e-3, 100)
+	s.Require().NoError(err)
+	strict, err := m.Eigen(DefaultEigenTolerance, 100)
+	s.Require().NoError(err)
+	s.LessOrEqual(eigen.Iterations, strict.Iterations)
+}
+
+// Run all tests
+func TestEigenTestSuite(t *testing.T) {
+	suite.Run(t, new(EigenTestSuite))
+}
@@ -0,0 +1,118 @@
+// Package matrix parses, validates and operates on square matrices of integers.
+//
+// It has no dependency on net/http, so the same validated logic used by the matrix
+// service can run in-process:
+//
+//	m, err := matrix.ParseCSV(file)
+//	if err != nil {
+//		return err // such as "matrix is not square: 3 rows and 2 columns"
+//	}
+//	fmt.Println(m.Transpose().Values, m.Sum(), m.Product())
+package matrix
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// MaxDenseValues caps the values of the matrices that are stored with every value, when they are
+// read from array formats or expanded from sparse ones, so that a small upload declaring a huge
+// size cannot exhaust the memory.
+const MaxDenseValues = 1 << 22
+
+// Matrix is a square matrix of integers. Values are stored row by row.
+type Matrix struct {
+	Values [][]int64
+	// RowLabels and ColLabels name the rows and columns, they are nil for input without labels
+	RowLabels []string
+	ColLabels []string
+}
+
+// New returns a Matrix of the values, or an error if they are not square.
+func New(values [][]int64) (*Matrix, error) {
+	if len(values) == 0 {
+		return nil, fmt.Errorf("empty matrix")
+	}
+	for i, row := range values {
+		if len(row) != len(values) {
+			return nil, fmt.Errorf("matrix is not square: row %d has %d columns, expected %d", i+1, len(row), len(values))
+		}
+	}
+	return &Matrix{Values: values}, nil
+}
+
+// Size returns the number of rows, which is also the number of columns.
+func (m *Matrix) Size() int {
+	return len(m.Values)
+}
+
+// Transpose returns the matrix where the columns and rows are inverted, the column labels
+// become the row labels and the other way around.
+func (m *Matrix) Transpose() *Matrix {
+	transposed := make([][]int64, len(m.Values))
+	// Each row is a list of integers, such as [1, 2, 3]
+	for _, row := range m.Values {
+		for idx, num := range row {
+			transposed[idx] = append(transposed[idx], num)
+		}
+	}
+	return &Matrix{Values: transposed, RowLabels: m.ColLabels, ColLabels: m.RowLabels}
+}
+
+// Flatten returns the values of the matrix row by row in a single list.
+func (m *Matrix) Flatten() []int64 {
+	flattened := make([]int64, 0, len(m.Values)*len(m.Values))
+	for _, row := range m.Values {
+		flattened = append(flattened, row...)
+	}
+	return flattened
+}
+
+// Sum returns the sum of the integers in the matrix.
+// It uses big.Int since the sum of values close to the int64 limits overflows int64.
+func (m *Matrix) Sum() *big.Int {
+	sum := new(big.Int)
+	for _, row := range m.Values {
+		for _, num := range row {
+			sum.Add(sum, big.NewInt(num))
+		}
+	}
+	return sum
+}
+
+// Product returns the product of the integers in the matrix.
+// It uses big.Int since the product of even a small matrix overflows int64.
+func (m *Matrix) Product() *big.Int {
+	product := big.NewInt(1)
+	for _, row := range m.Values {
+		for _, num := range row {
+			product.Mul(product, big.NewInt(num))
+		}
+	}
+	return product
+}
+
+// SumMod returns the sum of the integers in the matrix modulo mod, in [0, mod).
+func (m *Matrix) SumMod(mod *big.Int) *big.Int {
+	sum := new(big.Int)
+	for _, row := range m.Values {
+		for _, num := range row {
+			sum.Add(sum, big.NewInt(num))
+		}
+		sum.Mod(sum, mod)
+	}
+	return sum
+}
+
+// ProductMod returns the product of the integers in the matrix modulo mod, in [0, mod).
+// Reducing after every value keeps the product smaller than mod squared.
+func (m *Matrix) ProductMod(mod *big.Int) *big.Int {
+	product := new(big.Int).Mod(big.NewInt(1), mod)
+	for _, row := range m.Values {
+		for _, num := range row {
+			product.Mul(product, big.NewInt(num))
+			product.Mod(product, mod)
+		}
+	}
+	return product
+}
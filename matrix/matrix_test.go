@@ -0,0 +1,111 @@
+package matrix
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type MatrixTestSuite struct {
+	suite.Suite
+}
+
+// Test for New
+func (s *MatrixTestSuite) TestNew() {
+	m, err := New([][]int64{{1, 2}, {3, 4}})
+	s.NoError(err)
+	s.Equal(2, m.Size())
+
+	_, err = New(nil)
+	s.EqualError(err, "empty matrix")
+
+	_, err = New([][]int64{{1, 2}, {3}})
+	s.EqualError(err, "matrix is not square: row 2 has 1 columns, expected 2")
+}
+
+// Test for the matrix operations
+func (s *MatrixTestSuite) TestOperations() {
+	tests := []struct {
+		name              string
+		values            [][]int64
+		expectedTranspose [][]int64
+		expectedFlatten   []int64
+		expectedSum       string
+		expectedProduct   string
+		// expectedSumMod and expectedProductMod are modulo 7
+		expectedSumMod     int64
+		expectedProductMod int64
+	}{
+		{
+			name:               "valid 1*1 matrix",
+			values:             [][]int64{{-7}},
+			expectedTranspose:  [][]int64{{-7}},
+			expectedFlatten:    []int64{-7},
+			expectedSum:        "-7",
+			expectedProduct:    "-7",
+			expectedSumMod:     0,
+			expectedProductMod: 0,
+		},
+		{
+			name:               "valid 3*3 matrix",
+			values:             [][]int64{{1, 2, 3}, {4, 5, 6}, {7, 8, 9}},
+			expectedTranspose:  [][]int64{{1, 4, 7}, {2, 5, 8}, {3, 6, 9}},
+			expectedFlatten:    []int64{1, 2, 3, 4, 5, 6, 7, 8, 9},
+			expectedSum:        "45",
+			expectedProduct:    "362880",
+			expectedSumMod:     3,
+			expectedProductMod: 0,
+		},
+		{
+			name:               "valid 4*4 matrix",
+			values:             [][]int64{{1, 2, 3, 4}, {2, 2, -1, -10}, {3, 3, 5, -2}, {4, 3, 2, 1}},
+			expectedTranspose:  [][]int64{{1, 2, 3, 4}, {2, 2, 3, 3}, {3, -1, 5, 2}, {4, -10, -2, 1}},
+			expectedFlatten:    []int64{1, 2, 3, 4, 2, 2, -1, -10, 3, 3, 5, -2, 4, 3, 2, 1},
+			expectedSum:        "22",
+			expectedProduct:    "-2073600",
+			expectedSumMod:     1,
+			expectedProductMod: 3,
+		},
+		{
+			name:               "sum larger than int64",
+			values:             [][]int64{{math.MaxInt64, 1}, {1, 1}},
+			expectedTranspose:  [][]int64{{math.MaxInt64, 1}, {1, 1}},
+			expectedFlatten:    []int64{math.MaxInt64, 1, 1, 1},
+			expectedSum:        "9223372036854775810",
+			expectedProduct:    "9223372036854775807",
+			expectedSumMod:     3,
+			expectedProductMod: 0,
+		},
+		{
+			name:               "product larger than int64",
+			values:             [][]int64{{1 << 40, 1 << 40}, {1 << 40, 1}},
+			expectedTranspose:  [][]int64{{1 << 40, 1 << 40}, {1 << 40, 1}},
+			expectedFlatten:    []int64{1 << 40, 1 << 40, 1 << 40, 1},
+			expectedSum:        "3298534883329",
+			expectedProduct:    "1329227995784915872903807060280344576",
+			expectedSumMod:     0,
+			expectedProductMod: 1,
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			m, err := New(tc.values)
+			s.Require().NoError(err)
+
+			s.Equal(tc.expectedTranspose, m.Transpose().Values)
+			s.Equal(tc.expectedFlatten, m.Flatten())
+			s.Equal(tc.expectedSum, m.Sum().String())
+			s.Equal(tc.expectedProduct, m.Product().String())
+			s.Equal(tc.expectedSumMod, m.SumMod(big.NewInt(7)).Int64())
+			s.Equal(tc.expectedProductMod, m.ProductMod(big.NewInt(7)).Int64())
+		})
+	}
+}
+
+// Run all tests
+func TestMatrixTestSuite(t *testing.T) {
+	suite.Run(t, new(MatrixTestSuite))
+}
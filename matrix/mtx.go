@@ -0,0 +1,271 @@
+package matrix
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// MatrixMarketContentType is the content type of Matrix Market (.mtx) files.
+const MatrixMarketContentType = "application/x-matrix-market"
+
+// maxMatrixMarketSize caps the size declared in the header of Matrix Market input.
+const maxMatrixMarketSize = 1 << 24
+
+// ReadMatrixMarket reads a square matrix of integers in the Matrix Market exchange format
+// (https://math.nist.gov/MatrixMarket/formats.html). The array format is returned as a dense
+// Matrix and the coordinate format as a Sparse matrix, the other result is nil.
+//
+// Integer, pattern and real fields are accepted, real values must be whole numbers. Symmetric,
+// skew-symmetric and hermitian matrices are expanded to their general form.
+func ReadMatrixMarket(r io.Reader) (*Matrix, *Sparse, error) {
+	mr := &mtxReader{scanner: bufio.NewScanner(r)}
+	mr.scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+
+	// 1st Step: read the banner, such as "%%MatrixMarket matrix coordinate integer general"
+	if !mr.scanner.Scan() {
+		if err := mr.scanner.Err(); err != nil {
+			return nil, nil, mr.errorf("%v", err)
+		}
+		return nil, nil, fmt.Errorf("failed to parse matrix market: file is empty")
+	}
+	mr.line++
+	banner := strings.Fields(strings.ToLower(mr.scanner.Text()))
+	if len(banner) != 5 || banner[0] != "%%matrixmarket" || banner[1] != "matrix" {
+		return nil, nil, mr.errorf("missing \"%%%%MatrixMarket matrix <format> <field> <symmetry>\" banner")
+	}
+	format, field, symmetry := banner[2], banner[3], banner[4]
+	if format != "coordinate" && format != "array" {
+		return nil, nil, mr.errorf("unsupported format %q, expected coordinate or array", format)
+	}
+	switch field {
+	case "integer", "real", "double":
+	case "pattern":
+		if format == "array" {
+			return nil, nil, mr.errorf("pattern field is only valid for the coordinate format")
+		}
+	default:
+		return nil, nil, mr.errorf("unsupported field %q, expected integer, real or pattern", field)
+	}
+	if symmetry != "general" && symmetry != "symmetric" && symmetry != "skew-symmetric" && symmetry != "hermitian" {
+		return nil, nil, mr.errorf("unsupported symmetry %q", symmetry)
+	}
+
+	// 2nd Step: read the size line, "rows cols" for array and "rows cols entries" for coordinate
+	sizeFields, err := mr.next()
+	if err != nil {
+		return nil, nil, err
+	}
+	wantFields := 2
+	if format == "coordinate" {
+		wantFields = 3
+	}
+	if len(sizeFields) != wantFields {
+		return nil, nil, mr.errorf("size line must have %d values", wantFields)
+	}
+	size := make([]int, wantFields)
+	for i, f := range sizeFields {
+		if size[i], err = strconv.Atoi(f); err != nil || size[i] < 0 {
+			return nil, nil, mr.errorf("size %q is not a non-negative integer", f)
+		}
+	}
+	rows, cols := size[0], size[1]
+	if rows == 0 || cols == 0 {
+		return nil, nil, fmt.Errorf("empty matrix")
+	}
+	if rows != cols {
+		return nil, nil, fmt.Errorf("matrix is not square: %d rows and %d columns", rows, cols)
+	}
+	if rows > maxMatrixMarketSize {
+		return nil, nil, mr.errorf("matrix size %d is larger than the limit of %d", rows, maxMatrixMarketSize)
+	}
+	n := rows
+
+	// 3rd Step: read the values
+	if format == "array" {
+		if n > MaxDenseValues/n {
+			return nil, nil, mr.errorf("array of %dx%d values is larger than the limit of %d values: use the coordinate format", n, n, MaxDenseValues)
+		}
+		values, err := mr.readArray(n, field, symmetry)
+		if err != nil {
+			return nil, nil, err
+		}
+		return &Matrix{Values: values}, nil, nil
+	}
+
+	sparse, err := mr.readCoordinate(n, size[2], field, symmetry)
+	if err != nil {
+		return nil, nil, err
+	}
+	return nil, sparse, nil
+}
+
+// mtxReader reads the lines of Matrix Market input, skipping comments and blank lines.
+type mtxReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+// next returns the fields of the next data line, or io.ErrUnexpectedEOF at the end of the input.
+func (mr *mtxReader) next() ([]string, error) {
+	for mr.scanner.Scan() {
+		mr.line++
+		text := strings.TrimSpace(mr.scanner.Text())
+		if text == "" || strings.HasPrefix(text, "%") {
+			continue
+		}
+		return strings.Fields(text), nil
+	}
+	if err := mr.scanner.Err(); err != nil {
+		return nil, mr.errorf("%v", err)
+	}
+	return nil, fmt.Errorf("failed to parse matrix market: %w", io.ErrUnexpectedEOF)
+}
+
+func (mr *mtxReader) errorf(format string, args ...any) error {
+	return fmt.Errorf("failed to parse matrix market: line %d: %s", mr.line, fmt.Sprintf(format, args...))
+}
+
+// readArray reads the values of the array format, stored column by column. Only the lower
+// triangle is stored for symmetric matrices, without the diagonal for skew-symmetric ones.
+func (mr *mtxReader) readArray(n int, field string, symmetry string) ([][]int64, error) {
+	values := make([][]int64, n)
+	for i := range values {
+		values[i] = make([]int64, n)
+	}
+
+	for j := range n {
+		start := 0
+		switch symmetry {
+		case "symmetric", "hermitian":
+			start = j
+		case "skew-symmetric":
+			start = j + 1
+		}
+		for i := start; i < n; i++ {
+			fields, err := mr.next()
+			if err != nil {
+				return nil, err
+			}
+			if len(fields) != 1 {
+				return nil, mr.errorf("expected 1 value for row %d, column %d", i+1, j+1)
+			}
+			num, err := parseMatrixMarketValue(fields[0], field)
+			if err != nil {
+				return nil, fmt.Errorf("matrix value at row %d, column %d is not an integer: %v", i+1, j+1, err)
+			}
+			values[i][j] = num
+			mirrorEntry(values, i, j, num, symmetry)
+		}
+	}
+
+	if _, err := mr.next(); err == nil {
+		return nil, mr.errorf("more values than the %dx%d matrix holds", n, n)
+	}
+	return values, nil
+}
+
+// readCoordinate reads the "row column [value]" entries of the coordinate format.
+func (mr *mtxReader) readCoordinate(n int, count int, field string, symmetry string) (*Sparse, error) {
+	wantFields := 3
+	if field == "pattern" {
+		wantFields = 2
+	}
+
+	entries := make([]Entry, 0, min(count, 1<<20))
+	for k := range count {
+		fields, err := mr.next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse matrix market: expected %d entries, found %d", count, k)
+		}
+		if len(fields) != wantFields {
+			return nil, mr.errorf("entry must have %d values", wantFields)
+		}
+		i, errRow := strconv.Atoi(fields[0])
+		j, errCol := strconv.Atoi(fields[1])
+		if errRow != nil || errCol != nil || i < 1 || j < 1 || i > n || j > n {
+			return nil, mr.errorf("entry position %s %s is outside the %dx%d matrix", fields[0], fields[1], n, n)
+		}
+
+		num := int64(1)
+		if field != "pattern" {
+			if num, err = parseMatrixMarketValue(fields[2], field); err != nil {
+				return nil, fmt.Errorf("matrix value at row %d, column %d is not an integer: %v", i, j, err)
+			}
+		}
+		entries = append(entries, Entry{Row: i - 1, Col: j - 1, Value: num})
+
+		// Only the lower triangle is stored for symmetric matrices
+		if symmetry != "general" {
+			if j > i {
+				return nil, mr.errorf("entry at row %d, column %d is above the diagonal of a %s matrix", i, j, symmetry)
+			}
+			if i != j {
+				mirrored := num
+				if symmetry == "skew-symmetric" {
+					mirrored = -num
+				}
+				entries = append(entries, Entry{Row: j - 1, Col: i - 1, Value: mirrored})
+			} else if symmetry == "skew-symmetric" {
+				return nil, mr.errorf("entry at row %d, column %d is on the diagonal of a skew-symmetric matrix", i, j)
+			}
+		}
+	}
+
+	if _, err := mr.next(); err == nil {
+		return nil, mr.errorf("more than the %d entries declared in the size line", count)
+	}
+	return NewSparse(n, entries)
+}
+
+// mirrorEntry sets the entry above the diagonal of a symmetric or skew-symmetric matrix.
+func mirrorEntry(values [][]int64, i int, j int, num int64, symmetry string) {
+	switch symmetry {
+	case "symmetric", "hermitian":
+		values[j][i] = num
+	case "skew-symmetric":
+		values[j][i] = -num
+	}
+}
+
+// parseMatrixMarketValue parses an integer, or a real that is a whole number such as "3.0e2".
+func parseMatrixMarketValue(val string, field string) (int64, error) {
+	if field == "integer" {
+		return strconv.ParseInt(val, 10, 64)
+	}
+	f, err := strconv.ParseFloat(val, 64)
+	if err != nil {
+		return 0, err
+	}
+	if f != math.Trunc(f) || math.Abs(f) > 1<<53 {
+		return 0, fmt.Errorf("%q is not a whole number", val)
+	}
+	return int64(f), nil
+}
+
+// WriteMatrixMarketArray writes the matrix in the Matrix Market array format.
+func WriteMatrixMarketArray(w io.Writer, m *Matrix) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "%%%%MatrixMarket matrix array integer general\n%d %d\n", m.Size(), m.Size())
+	// Values are stored column by column
+	for j := range m.Size() {
+		for i := range m.Size() {
+			bw.WriteString(strconv.FormatInt(m.Values[i][j], 10))
+			bw.WriteByte('\n')
+		}
+	}
+	return bw.Flush()
+}
+
+// WriteMatrixMarketCoordinate writes the nonzero entries of the matrix in the Matrix Market coordinate format.
+func WriteMatrixMarketCoordinate(w io.Writer, s *Sparse) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "%%%%MatrixMarket matrix coordinate integer general\n%d %d %d\n", s.N, s.N, len(s.Entries))
+	for _, e := range s.Entries {
+		fmt.Fprintf(bw, "%d %d %d\n", e.Row+1, e.Col+1, e.Value)
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,155 @@
+package matrix
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type MatrixMarketTestSuite struct {
+	suite.Suite
+}
+
+// Test for ReadMatrixMarket
+func (s *MatrixMarketTestSuite) TestReadMatrixMarket() {
+	tests := []struct {
+		name           string
+		input          string
+		expectedDense  [][]int64
+		expectedSparse *Sparse
+		errorSubstr    string
+	}{
+		{
+			name:          "array general",
+			input:         "%%MatrixMarket matrix array integer general\n% comment\n2 2\n1\n3\n2\n4\n",
+			expectedDense: [][]int64{{1, 2}, {3, 4}},
+		},
+		{
+			name:          "array symmetric with whole reals",
+			input:         "%%MatrixMarket matrix array real symmetric\n2 2\n1.0\n2e0\n3\n",
+			expectedDense: [][]int64{{1, 2}, {2, 3}},
+		},
+		{
+			name:          "array skew-symmetric",
+			input:         "%%MatrixMarket matrix array integer skew-symmetric\n2 2\n5\n",
+			expectedDense: [][]int64{{0, -5}, {5, 0}},
+		},
+		{
+			name:  "coordinate general",
+			input: "%%MatrixMarket matrix coordinate integer general\n3 3 2\n3 1 -4\n1 2 9\n",
+			expectedSparse: &Sparse{N: 3, Entries: []Entry{
+				{Row: 0, Col: 1, Value: 9},
+				{Row: 2, Col: 0, Value: -4},
+			}},
+		},
+		{
+			name:  "coordinate symmetric pattern",
+			input: "%%MatrixMarket matrix coordinate pattern symmetric\n2 2 2\n1 1\n2 1\n",
+			expectedSparse: &Sparse{N: 2, Entries: []Entry{
+				{Row: 0, Col: 0, Value: 1},
+				{Row: 0, Col: 1, Value: 1},
+				{Row: 1, Col: 0, Value: 1},
+			}},
+		},
+		{
+			name:        "empty input",
+			input:       "",
+			errorSubstr: "failed to parse matrix market: file is empty",
+		},
+		{
+			name:        "missing banner",
+			input:       "1,2\n3,4\n",
+			errorSubstr: "line 1: missing \"%%MatrixMarket matrix <format> <field> <symmetry>\" banner",
+		},
+		{
+			name:        "complex field",
+			input:       "%%MatrixMarket matrix coordinate complex general\n1 1 1\n1 1 1 0\n",
+			errorSubstr: "unsupported field \"complex\"",
+		},
+		{
+			name:        "not square",
+			input:       "%%MatrixMarket matrix coordinate integer general\n3 2 0\n",
+			errorSubstr: "matrix is not square: 3 rows and 2 columns",
+		},
+		{
+			name:        "entry outside the matrix",
+			input:       "%%MatrixMarket matrix coordinate integer general\n2 2 1\n3 1 1\n",
+			errorSubstr: "line 3: entry position 3 1 is outside the 2x2 matrix",
+		},
+		{
+			name:        "duplicate entry",
+			input:       "%%MatrixMarket matrix coordinate integer general\n2 2 2\n1 1 1\n1 1 2\n",
+			errorSubstr: "matrix has duplicate entry at row 1, column 1",
+		},
+		{
+			name:        "fewer entries than declared",
+			input:       "%%MatrixMarket matrix coordinate integer general\n2 2 3\n1 1 1\n",
+			errorSubstr: "expected 3 entries, found 1",
+		},
+		{
+			name:        "more values than declared",
+			input:       "%%MatrixMarket matrix array integer general\n1 1\n1\n2\n",
+			errorSubstr: "more values than the 1x1 matrix holds",
+		},
+		{
+			name:        "array larger than the dense limit",
+			input:       "%%MatrixMarket matrix array integer general\n200000 200000\n1\n",
+			errorSubstr: "line 2: array of 200000x200000 values is larger than the limit of 4194304 values: use the coordinate format",
+		},
+		{
+			name:        "fractional value",
+			input:       "%%MatrixMarket matrix array real general\n2 2\n1\n2\n3.5\n4\n",
+			errorSubstr: "matrix value at row 1, column 2 is not an integer: \"3.5\" is not a whole number",
+		},
+		{
+			name:        "entry above the diagonal of a symmetric matrix",
+			input:       "%%MatrixMarket matrix coordinate integer symmetric\n2 2 1\n1 2 1\n",
+			errorSubstr: "entry at row 1, column 2 is above the diagonal of a symmetric matrix",
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			dense, sparse, err := ReadMatrixMarket(strings.NewReader(tc.input))
+			if tc.errorSubstr != "" {
+				s.ErrorContains(err, tc.errorSubstr)
+				return
+			}
+			s.Require().NoError(err)
+			if tc.expectedDense != nil {
+				s.Nil(sparse)
+				s.Equal(tc.expectedDense, dense.Values)
+			} else {
+				s.Nil(dense)
+				s.Equal(tc.expectedSparse, sparse)
+			}
+		})
+	}
+}
+
+// Test that written Matrix Market files are read back unchanged
+func (s *MatrixMarketTestSuite) TestWriteMatrixMarket() {
+	m, err := New([][]int64{{1, 0, 3}, {0, 0, -6}, {7, 0, 9}})
+	s.Require().NoError(err)
+
+	var buf bytes.Buffer
+	s.Require().NoError(WriteMatrixMarketArray(&buf, m))
+	s.Equal("%%MatrixMarket matrix array integer general\n3 3\n1\n0\n7\n0\n0\n0\n3\n-6\n9\n", buf.String())
+	dense, _, err := ReadMatrixMarket(&buf)
+	s.NoError(err)
+	s.Equal(m.Values, dense.Values)
+
+	buf.Reset()
+	s.Require().NoError(WriteMatrixMarketCoordinate(&buf, m.Sparse()))
+	s.Equal("%%MatrixMarket matrix coordinate integer general\n3 3 5\n1 1 1\n1 3 3\n2 3 -6\n3 1 7\n3 3 9\n", buf.String())
+	_, sparse, err := ReadMatrixMarket(&buf)
+	s.NoError(err)
+	s.Equal(m.Values, sparse.Dense().Values)
+}
+
+// Run all tests
+func TestMatrixMarketTestSuite(t *testing.T) {
+	suite.Run(t, new(MatrixMarketTestSuite))
+}
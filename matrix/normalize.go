@@ -0,0 +1,101 @@
+package matrix
+
+import (
+	"bytes"
+	"strings"
+)
+
+// Normalization is a change made to lenient input by Parser.ReadCSVNormalized.
+type Normalization string
+
+const (
+	// NormalizedBOM is a stripped UTF-8 byte order mark
+	NormalizedBOM Normalization = "bom"
+	// NormalizedLineEndings are CRLF or CR line endings converted to LF
+	NormalizedLineEndings Normalization = "line-endings"
+	// NormalizedComments are skipped lines starting with #
+	NormalizedComments Normalization = "comments"
+	// NormalizedBlankLines are skipped lines with only whitespace or delimiters
+	NormalizedBlankLines Normalization = "blank-lines"
+	// NormalizedWhitespace is whitespace trimmed around values, such as in "1, 2, 3"
+	NormalizedWhitespace Normalization = "whitespace"
+)
+
+// utf8BOM starts files saved as "UTF-8 with BOM", such as CSV exports of Excel.
+var utf8BOM = []byte("\xef\xbb\xbf")
+
+// normalizations collects the normalizations applied to the input, in order.
+type normalizations []Normalization
+
+func (n *normalizations) add(normalization Normalization) {
+	for _, applied := range *n {
+		if applied == normalization {
+			return
+		}
+	}
+	*n = append(*n, normalization)
+}
+
+// list returns the applied normalizations, or nil if the input was not changed.
+func (n normalizations) list() []Normalization {
+	if len(n) == 0 {
+		return nil
+	}
+	return n
+}
+
+// normalizeText strips the BOM, converts line endings and removes comment and blank lines,
+// before the delimiter is detected.
+func (n *normalizations) normalizeText(data []byte) []byte {
+	if bytes.HasPrefix(data, utf8BOM) {
+		data = data[len(utf8BOM):]
+		n.add(NormalizedBOM)
+	}
+	if bytes.ContainsRune(data, '\r') {
+		data = bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+		data = bytes.ReplaceAll(data, []byte("\r"), []byte("\n"))
+		n.add(NormalizedLineEndings)
+	}
+
+	lines := bytes.Split(data, []byte("\n"))
+	kept := lines[:0]
+	for i, line := range lines {
+		trimmed := bytes.TrimSpace(line)
+		switch {
+		case bytes.HasPrefix(trimmed, []byte("#")):
+			n.add(NormalizedComments)
+		case len(trimmed) == 0:
+			// The newline that ends the last line is not a blank line
+			if i < len(lines)-1 || len(line) > 0 {
+				n.add(NormalizedBlankLines)
+			}
+		default:
+			kept = append(kept, line)
+		}
+	}
+	return bytes.Join(kept, []byte("\n"))
+}
+
+// normalizeRecords trims the whitespace around values and removes records without any
+// value, such as the ",,," rows of spreadsheet exports.
+func (n *normalizations) normalizeRecords(records [][]string) [][]string {
+	kept := records[:0]
+	for _, record := range records {
+		blank := true
+		for j, val := range record {
+			if trimmed := strings.TrimSpace(val); trimmed != val {
+				record[j] = trimmed
+				n.add(NormalizedWhitespace)
+			}
+			if record[j] != "" {
+				blank = false
+			}
+		}
+		if blank {
+			n.add(NormalizedBlankLines)
+			continue
+		}
+		kept = append(kept, record)
+	}
+	return kept
+}
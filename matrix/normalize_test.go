@@ -0,0 +1,82 @@
+package matrix
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type NormalizeTestSuite struct {
+	suite.Suite
+}
+
+// Test for ReadCSVNormalized in lenient mode
+func (s *NormalizeTestSuite) TestReadCSVNormalized() {
+	tests := []struct {
+		name                   string
+		input                  string
+		expectedRecords        [][]string
+		expectedNormalizations []Normalization
+	}{
+		{
+			name:            "clean input is not changed",
+			input:           "1,2\n3,4\n",
+			expectedRecords: [][]string{{"1", "2"}, {"3", "4"}},
+		},
+		{
+			name:                   "excel export",
+			input:                  "\xef\xbb\xbf1, 2\r\n3, 4\r\n\r\n",
+			expectedRecords:        [][]string{{"1", "2"}, {"3", "4"}},
+			expectedNormalizations: []Normalization{NormalizedBOM, NormalizedLineEndings, NormalizedBlankLines, NormalizedWhitespace},
+		},
+		{
+			name:                   "comment lines",
+			input:                  "# exported matrix\n1;2\n  # second row\n3;4\n",
+			expectedRecords:        [][]string{{"1", "2"}, {"3", "4"}},
+			expectedNormalizations: []Normalization{NormalizedComments},
+		},
+		{
+			name:                   "rows of empty values",
+			input:                  "1,2\n3,4\n,\n , \n",
+			expectedRecords:        [][]string{{"1", "2"}, {"3", "4"}},
+			expectedNormalizations: []Normalization{NormalizedBlankLines, NormalizedWhitespace},
+		},
+		{
+			name:                   "whitespace separated with blank lines",
+			input:                  "1 2\n\n3 4",
+			expectedRecords:        [][]string{{"1", "2"}, {"3", "4"}},
+			expectedNormalizations: []Normalization{NormalizedBlankLines},
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			records, normalizations, err := Parser{Delimiter: DelimiterAuto, Lenient: true}.ReadCSVNormalized(strings.NewReader(tc.input))
+			s.Require().NoError(err)
+			s.Equal(tc.expectedRecords, records)
+			s.Equal(tc.expectedNormalizations, normalizations)
+		})
+	}
+}
+
+// Test that strict mode stays the default and hints at lenient mode
+func (s *NormalizeTestSuite) TestStrictMode() {
+	_, err := ParseCSV(strings.NewReader("1, 2\n3,4"))
+	s.ErrorContains(err, "matrix has a header row or non-integer value at row 1, column 2: \" 2\" has surrounding whitespace, use lenient mode to trim it")
+
+	_, err = ParseCSV(strings.NewReader("\xef\xbb\xbf1,2\n3,4"))
+	s.ErrorContains(err, "matrix has a header row or non-integer value at row 1, column 1")
+
+	_, err = ParseCSV(strings.NewReader("# comment\n1,2\n3,4"))
+	s.ErrorContains(err, "matrix has a header row or non-integer value at row 1, column 1")
+
+	m, err := Parser{Lenient: true}.ParseCSV(strings.NewReader("# comment\n1, 2\n3, 4"))
+	s.NoError(err)
+	s.Equal([][]int64{{1, 2}, {3, 4}}, m.Values)
+}
+
+// Run all tests
+func TestNormalizeTestSuite(t *testing.T) {
+	suite.Run(t, new(NormalizeTestSuite))
+}
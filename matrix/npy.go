@@ -0,0 +1,238 @@
+package matrix
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// NPYContentType is the content type of NumPy .npy files.
+const NPYContentType = "application/x-npy"
+
+// npyMagic starts every .npy file (https://numpy.org/doc/stable/reference/generated/numpy.lib.format.html).
+var npyMagic = []byte("\x93NUMPY")
+
+var (
+	npyDescr   = regexp.MustCompile(`'descr'\s*:\s*'([<>|=])([iuf])(\d+)'`)
+	npyFortran = regexp.MustCompile(`'fortran_order'\s*:\s*(True|False)`)
+	npyShape   = regexp.MustCompile(`'shape'\s*:\s*\(([^)]*)\)`)
+)
+
+// ReadNPY reads a square matrix of integers from a NumPy .npy file. Signed and unsigned
+// integer and float dtypes are accepted in C or Fortran order, float values must be whole numbers.
+func ReadNPY(r io.Reader) (*Matrix, error) {
+	br := bufio.NewReader(r)
+
+	// 1st Step: read the magic string, the version and the header length
+	prefix := make([]byte, len(npyMagic)+2)
+	if _, err := io.ReadFull(br, prefix); err != nil {
+		if err == io.EOF {
+			return nil, fmt.Errorf("failed to parse npy: file is empty")
+		}
+		return nil, fmt.Errorf("failed to parse npy: missing \\x93NUMPY magic string")
+	}
+	if !bytes.Equal(prefix[:len(npyMagic)], npyMagic) {
+		return nil, fmt.Errorf("failed to parse npy: missing \\x93NUMPY magic string")
+	}
+
+	var headerLen int
+	switch major := prefix[len(npyMagic)]; major {
+	case 1:
+		var n uint16
+		if err := binary.Read(br, binary.LittleEndian, &n); err != nil {
+			return nil, fmt.Errorf("failed to parse npy: truncated header")
+		}
+		headerLen = int(n)
+	case 2, 3:
+		var n uint32
+		if err := binary.Read(br, binary.LittleEndian, &n); err != nil || n > 1<<20 {
+			return nil, fmt.Errorf("failed to parse npy: truncated header")
+		}
+		headerLen = int(n)
+	default:
+		return nil, fmt.Errorf("failed to parse npy: unsupported format version %d", major)
+	}
+
+	// 2nd Step: parse the header, a Python dict such as
+	// {'descr': '<i8', 'fortran_order': False, 'shape': (3, 3), }
+	header := make([]byte, headerLen)
+	if _, err := io.ReadFull(br, header); err != nil {
+		return nil, fmt.Errorf("failed to parse npy: truncated header")
+	}
+	descr := npyDescr.FindSubmatch(header)
+	if descr == nil {
+		return nil, fmt.Errorf("failed to parse npy: unsupported dtype in header %q, expected an integer or float dtype", strings.TrimSpace(string(header)))
+	}
+	dtype, err := newNPYDtype(string(descr[1]), descr[2][0], string(descr[3]))
+	if err != nil {
+		return nil, err
+	}
+	fortran := npyFortran.FindSubmatch(header)
+	if fortran == nil {
+		return nil, fmt.Errorf("failed to parse npy: missing fortran_order in header")
+	}
+	shape, err := parseNPYShape(header)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3rd Step: validate the shape like ValidateSquare does for csv
+	if len(shape) != 2 {
+		return nil, fmt.Errorf("npy array has %d dimensions, expected a 2-dimensional matrix", len(shape))
+	}
+	rows, cols := shape[0], shape[1]
+	if rows == 0 || cols == 0 {
+		return nil, fmt.Errorf("empty matrix")
+	}
+	if rows != cols {
+		return nil, fmt.Errorf("matrix is not square: %d rows and %d columns", rows, cols)
+	}
+	// The values are allocated before they are read, so a tiny file could declare a huge shape
+	if rows > MaxDenseValues/cols {
+		return nil, fmt.Errorf("failed to parse npy: %dx%d matrix is larger than the limit of %d values", rows, cols, MaxDenseValues)
+	}
+	n := rows
+
+	// 4th Step: read the values, stored row by row, or column by column in Fortran order
+	values := make([][]int64, n)
+	for i := range values {
+		values[i] = make([]int64, n)
+	}
+	buf := make([]byte, dtype.size)
+	for k := range n * n {
+		if _, err := io.ReadFull(br, buf); err != nil {
+			return nil, fmt.Errorf("failed to parse npy: expected %d values, found %d", n*n, k)
+		}
+		i, j := k/n, k%n
+		if string(fortran[1]) == "True" {
+			i, j = k%n, k/n
+		}
+		num, err := dtype.decode(buf)
+		if err != nil {
+			return nil, fmt.Errorf("matrix value at row %d, column %d is not an integer: %v", i+1, j+1, err)
+		}
+		values[i][j] = num
+	}
+
+	return &Matrix{Values: values}, nil
+}
+
+// npyDtype is a supported numeric dtype of a .npy file.
+type npyDtype struct {
+	order binary.ByteOrder
+	kind  byte
+	size  int
+}
+
+func newNPYDtype(byteOrder string, kind byte, size string) (npyDtype, error) {
+	dtype := npyDtype{order: binary.LittleEndian, kind: kind}
+	if byteOrder == ">" {
+		dtype.order = binary.BigEndian
+	}
+	dtype.size, _ = strconv.Atoi(size)
+
+	valid := map[byte][]int{'i': {1, 2, 4, 8}, 'u': {1, 2, 4, 8}, 'f': {4, 8}}
+	for _, s := range valid[kind] {
+		if s == dtype.size {
+			return dtype, nil
+		}
+	}
+	return dtype, fmt.Errorf("failed to parse npy: unsupported dtype %s%c%s, expected an integer or float dtype", byteOrder, kind, size)
+}
+
+// decode returns the value in buf as an int64.
+func (d npyDtype) decode(buf []byte) (int64, error) {
+	var bits uint64
+	switch d.size {
+	case 1:
+		bits = uint64(buf[0])
+	case 2:
+		bits = uint64(d.order.Uint16(buf))
+	case 4:
+		bits = uint64(d.order.Uint32(buf))
+	case 8:
+		bits = d.order.Uint64(buf)
+	}
+
+	switch d.kind {
+	case 'i':
+		// Sign extend the value to 64 bits
+		shift := 64 - 8*d.size
+		return int64(bits<<shift) >> shift, nil
+	case 'u':
+		if bits > math.MaxInt64 {
+			return 0, fmt.Errorf("%d is larger than the int64 maximum", bits)
+		}
+		return int64(bits), nil
+	}
+
+	f := math.Float64frombits(bits)
+	if d.size == 4 {
+		f = float64(math.Float32frombits(uint32(bits)))
+	}
+	if f != math.Trunc(f) || math.IsInf(f, 0) {
+		return 0, fmt.Errorf("%v is not a whole number", f)
+	}
+	if math.Abs(f) >= 1<<63 {
+		return 0, fmt.Errorf("%v is out of the int64 range", f)
+	}
+	return int64(f), nil
+}
+
+// parseNPYShape returns the dimensions of the shape tuple in the header, such as "(3, 3)".
+func parseNPYShape(header []byte) ([]int, error) {
+	match := npyShape.FindSubmatch(header)
+	if match == nil {
+		return nil, fmt.Errorf("failed to parse npy: missing shape in header")
+	}
+
+	var shape []int
+	for _, field := range strings.Split(string(match[1]), ",") {
+		field = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(field), "L"))
+		if field == "" {
+			continue
+		}
+		dim, err := strconv.Atoi(field)
+		if err != nil || dim < 0 {
+			return nil, fmt.Errorf("failed to parse npy: invalid shape (%s)", match[1])
+		}
+		shape = append(shape, dim)
+	}
+	return shape, nil
+}
+
+// WriteNPY writes the values as a little-endian int64 .npy array of the given shape, in C order.
+// An empty shape writes a 0-dimensional array holding a single value.
+func WriteNPY(w io.Writer, shape []int, values []int64) error {
+	dims := make([]string, len(shape))
+	for i, dim := range shape {
+		dims[i] = strconv.Itoa(dim)
+	}
+	shapeText := strings.Join(dims, ", ")
+	if len(shape) == 1 {
+		shapeText += ","
+	}
+	header := fmt.Sprintf("{'descr': '<i8', 'fortran_order': False, 'shape': (%s), }", shapeText)
+
+	// Pad the header with spaces so that the data starts at a multiple of 64 bytes
+	prefixLen := len(npyMagic) + 2 + 2
+	padding := 64 - (prefixLen+len(header)+1)%64
+	if padding == 64 {
+		padding = 0
+	}
+	header += strings.Repeat(" ", padding) + "\n"
+
+	bw := bufio.NewWriter(w)
+	bw.Write(npyMagic)
+	bw.Write([]byte{1, 0})
+	binary.Write(bw, binary.LittleEndian, uint16(len(header)))
+	bw.WriteString(header)
+	binary.Write(bw, binary.LittleEndian, values)
+	return bw.Flush()
+}
@@ -0,0 +1,167 @@
+package matrix
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type NPYTestSuite struct {
+	suite.Suite
+}
+
+// npyFile builds a version 1.0 .npy file with the header and the raw values.
+func npyFile(descr string, fortran bool, shape string, order binary.ByteOrder, values any) []byte {
+	fortranText := "False"
+	if fortran {
+		fortranText = "True"
+	}
+	header := fmt.Sprintf("{'descr': '%s', 'fortran_order': %s, 'shape': %s, }\n", descr, fortranText, shape)
+
+	var buf bytes.Buffer
+	buf.Write(npyMagic)
+	buf.Write([]byte{1, 0})
+	binary.Write(&buf, binary.LittleEndian, uint16(len(header)))
+	buf.WriteString(header)
+	binary.Write(&buf, order, values)
+	return buf.Bytes()
+}
+
+// Test for ReadNPY
+func (s *NPYTestSuite) TestReadNPY() {
+	tests := []struct {
+		name           string
+		input          []byte
+		expectedMatrix [][]int64
+		errorSubstr    string
+	}{
+		{
+			name:           "int64 in C order",
+			input:          npyFile("<i8", false, "(2, 2)", binary.LittleEndian, []int64{1, 2, 3, 4}),
+			expectedMatrix: [][]int64{{1, 2}, {3, 4}},
+		},
+		{
+			name:           "int64 in Fortran order",
+			input:          npyFile("<i8", true, "(2, 2)", binary.LittleEndian, []int64{1, 3, 2, 4}),
+			expectedMatrix: [][]int64{{1, 2}, {3, 4}},
+		},
+		{
+			name:           "big-endian int16",
+			input:          npyFile(">i2", false, "(2, 2)", binary.BigEndian, []int16{-1, 2, -300, 4}),
+			expectedMatrix: [][]int64{{-1, 2}, {-300, 4}},
+		},
+		{
+			name:           "uint8",
+			input:          npyFile("|u1", false, "(1, 1)", binary.LittleEndian, []uint8{255}),
+			expectedMatrix: [][]int64{{255}},
+		},
+		{
+			name:           "whole float64 values",
+			input:          npyFile("<f8", false, "(2, 2)", binary.LittleEndian, []float64{1, -2, 3e2, 0}),
+			expectedMatrix: [][]int64{{1, -2}, {300, 0}},
+		},
+		{
+			name:           "whole float32 values",
+			input:          npyFile("<f4", false, "(1, 1)", binary.LittleEndian, []float32{7}),
+			expectedMatrix: [][]int64{{7}},
+		},
+		{
+			name:        "empty input",
+			input:       nil,
+			errorSubstr: "failed to parse npy: file is empty",
+		},
+		{
+			name:        "csv input",
+			input:       []byte("1,2\n3,4\n"),
+			errorSubstr: "failed to parse npy: missing \\x93NUMPY magic string",
+		},
+		{
+			name:        "complex dtype",
+			input:       npyFile("<c16", false, "(1, 1)", binary.LittleEndian, []float64{1, 0}),
+			errorSubstr: "unsupported dtype in header",
+		},
+		{
+			name:        "float16 dtype",
+			input:       npyFile("<f2", false, "(1, 1)", binary.LittleEndian, []uint16{0}),
+			errorSubstr: "unsupported dtype <f2",
+		},
+		{
+			name:        "1-dimensional array",
+			input:       npyFile("<i8", false, "(4,)", binary.LittleEndian, []int64{1, 2, 3, 4}),
+			errorSubstr: "npy array has 1 dimensions, expected a 2-dimensional matrix",
+		},
+		{
+			name:        "not square",
+			input:       npyFile("<i8", false, "(3, 2)", binary.LittleEndian, []int64{1, 2, 3, 4, 5, 6}),
+			errorSubstr: "matrix is not square: 3 rows and 2 columns",
+		},
+		{
+			name:        "empty matrix",
+			input:       npyFile("<i8", false, "(0, 0)", binary.LittleEndian, []int64{}),
+			errorSubstr: "empty matrix",
+		},
+		{
+			name:        "fewer values than the shape",
+			input:       npyFile("<i8", false, "(2, 2)", binary.LittleEndian, []int64{1, 2, 3}),
+			errorSubstr: "failed to parse npy: expected 4 values, found 3",
+		},
+		{
+			name:        "fractional value",
+			input:       npyFile("<f8", true, "(2, 2)", binary.LittleEndian, []float64{1, 2, 3.5, 4}),
+			errorSubstr: "matrix value at row 1, column 2 is not an integer: 3.5 is not a whole number",
+		},
+		{
+			name:        "shape larger than the dense limit",
+			input:       npyFile("<i8", false, "(200000, 200000)", binary.LittleEndian, []int64{1}),
+			errorSubstr: "failed to parse npy: 200000x200000 matrix is larger than the limit of 4194304 values",
+		},
+		{
+			name:        "uint64 overflow",
+			input:       npyFile("<u8", false, "(1, 1)", binary.LittleEndian, []uint64{1 << 63}),
+			errorSubstr: "matrix value at row 1, column 1 is not an integer: 9223372036854775808 is larger than the int64 maximum",
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			m, err := ReadNPY(bytes.NewReader(tc.input))
+			if tc.errorSubstr != "" {
+				s.ErrorContains(err, tc.errorSubstr)
+				return
+			}
+			s.Require().NoError(err)
+			s.Equal(tc.expectedMatrix, m.Values)
+		})
+	}
+}
+
+// Test that written .npy files are aligned and read back unchanged
+func (s *NPYTestSuite) TestWriteNPY() {
+	m, err := New([][]int64{{1, -2, 3}, {4, 5, -6}, {7, 8, 9}})
+	s.Require().NoError(err)
+
+	var buf bytes.Buffer
+	s.Require().NoError(WriteNPY(&buf, []int{3, 3}, m.Flatten()))
+	s.Equal(128+9*8, buf.Len())
+	s.Contains(buf.String(), "{'descr': '<i8', 'fortran_order': False, 'shape': (3, 3), }")
+	read, err := ReadNPY(&buf)
+	s.NoError(err)
+	s.Equal(m.Values, read.Values)
+
+	buf.Reset()
+	s.Require().NoError(WriteNPY(&buf, []int{9}, m.Flatten()))
+	s.Contains(buf.String(), "'shape': (9,)")
+
+	buf.Reset()
+	s.Require().NoError(WriteNPY(&buf, nil, []int64{42}))
+	s.Contains(buf.String(), "'shape': ()")
+	s.Equal(128+8, buf.Len())
+}
+
+// Run all tests
+func TestNPYTestSuite(t *testing.T) {
+	suite.Run(t, new(NPYTestSuite))
+}
@@ -0,0 +1,214 @@
+package matrix
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Polynomial is a polynomial in x with integer coefficients, from the leading coefficient down
+// to the constant term.
+type Polynomial []*big.Int
+
+// Degree returns the degree of the polynomial.
+func (p Polynomial) Degree() int {
+	return len(p) - 1
+}
+
+// String returns the polynomial such as "x^3 - 15x^2 - 18x", without its zero terms and with
+// the coefficients of 1 and -1 left out.
+func (p Polynomial) String() string {
+	var b strings.Builder
+	for i, c := range p {
+		if c.Sign() == 0 {
+			continue
+		}
+		switch {
+		case b.Len() == 0 && c.Sign() < 0:
+			b.WriteString("-")
+		case b.Len() > 0 && c.Sign() < 0:
+			b.WriteString(" - ")
+		case b.Len() > 0:
+			b.WriteString(" + ")
+		}
+
+		power := p.Degree() - i
+		abs := new(big.Int).Abs(c)
+		if !abs.IsInt64() || abs.Int64() != 1 || power == 0 {
+			b.WriteString(abs.String())
+		}
+		switch power {
+		case 0:
+		case 1:
+			b.WriteString("x")
+		default:
+			fmt.Fprintf(&b, "x^%d", power)
+		}
+	}
+	if b.Len() == 0 {
+		return "0"
+	}
+	return b.String()
+}
+
+// CharacteristicPolynomial returns det(xI - A), computed exactly with the division-free
+// algorithm of Berkowitz in O(n^4) integer operations. Its leading coefficient is 1, the next
+// one is minus the trace and its constant term is (-1)^n times the determinant.
+func (m *Matrix) CharacteristicPolynomial() Polynomial {
+	n := m.Size()
+	a := m.bigValues()
+	tmp := new(big.Int)
+
+	// Start with the polynomial of the leading 1*1 submatrix, x - a[0][0]
+	poly := Polynomial{big.NewInt(1), new(big.Int).Neg(a[0][0])}
+	for k := 1; k < n; k++ {
+		// 1st Step: the first column of the Toeplitz matrix that maps the polynomial of the
+		// leading k*k submatrix A to the one of the (k+1)*(k+1) submatrix is
+		// 1, -a[k][k], -R*C, -R*A*C, ..., -R*A^(k-1)*C, with R the row k and C the column k of A
+		items := make([]*big.Int, k+2)
+		items[0] = big.NewInt(1)
+		items[1] = new(big.Int).Neg(a[k][k])
+		vector := make([]*big.Int, k)
+		for i := range k {
+			vector[i] = new(big.Int).Set(a[i][k])
+		}
+		for r := range k {
+			if r > 0 {
+				next := make([]*big.Int, k)
+				for i := range k {
+					next[i] = new(big.Int)
+					for j := range k {
+						next[i].Add(next[i], tmp.Mul(a[i][j], vector[j]))
+					}
+				}
+				vector = next
+			}
+			item := new(big.Int)
+			for j := range k {
+				item.Sub(item, tmp.Mul(a[k][j], vector[j]))
+			}
+			items[r+2] = item
+		}
+
+		// 2nd Step: multiply the Toeplitz matrix with the polynomial
+		next := make(Polynomial, k+2)
+		for i := range next {
+			next[i] = new(big.Int)
+			for j := max(i-len(items)+1, 0); j <= min(i, k); j++ {
+				next[i].Add(next[i], tmp.Mul(items[i-j], poly[j]))
+			}
+		}
+		poly = next
+	}
+	return poly
+}
+
+// MinimalPolynomial returns the monic polynomial of least degree that is zero at the matrix,
+// the characteristic polynomial divided by the greatest common divisor of the entries of the
+// adjugate of xI - A, which are its (n-1)*(n-1) minors.
+func (m *Matrix) MinimalPolynomial() Polynomial {
+	p := m.CharacteristicPolynomial()
+	n := m.Size()
+	a := m.bigValues()
+
+	// The adjugate is the sum of M_k*x^(n-1-k), with M_0 = I and M_k = A*M_(k-1) + p[k]*I, as
+	// (xI - A)*adj(xI - A) = p(x)*I
+	adjugate := make([][][]*big.Int, n)
+	adjugate[0] = m.Power(0, nil)
+	for k := 1; k < n; k++ {
+		adjugate[k] = multiplyBig(a, adjugate[k-1], nil)
+		for i := range n {
+			adjugate[k][i][i].Add(adjugate[k][i][i], p[k])
+		}
+	}
+
+	var divisor Polynomial
+	for i := range n {
+		for j := range n {
+			entry := make(Polynomial, n)
+			for k := range n {
+				entry[k] = adjugate[k][i][j]
+			}
+			divisor = gcdPolynomial(divisor, entry)
+			if divisor.Degree() == 0 {
+				return p
+			}
+		}
+	}
+	return quoPolynomial(p, divisor)
+}
+
+// gcdPolynomial returns the greatest common divisor of the polynomials, primitive and with a
+// positive leading coefficient, or an empty polynomial when both are zero. The primitive
+// pseudo-remainder sequence keeps the coefficients integers and small.
+func gcdPolynomial(a, b Polynomial) Polynomial {
+	a, b = primitive(a), primitive(b)
+	for len(b) > 0 {
+		a, b = b, primitive(pseudoRemainder(a, b))
+	}
+	if len(a) > 0 && a[0].Sign() < 0 {
+		for _, c := range a {
+			c.Neg(c)
+		}
+	}
+	return a
+}
+
+// pseudoRemainder returns the remainder of lc(b)^k*a divided by b, which has integer
+// coefficients, without its leading zeros.
+func pseudoRemainder(a, b Polynomial) Polynomial {
+	r := trim(a)
+	tmp := new(big.Int)
+	for len(r) >= len(b) {
+		lead := new(big.Int).Set(r[0])
+		next := make(Polynomial, len(r)-1)
+		for i := range next {
+			next[i] = new(big.Int).Mul(b[0], r[i+1])
+			if i+1 < len(b) {
+				next[i].Sub(next[i], tmp.Mul(lead, b[i+1]))
+			}
+		}
+		r = trim(next)
+	}
+	return r
+}
+
+// primitive returns a copy of the polynomial without its leading zeros, divided by the greatest
+// common divisor of its coefficients.
+func primitive(p Polynomial) Polynomial {
+	p = trim(p)
+	content := new(big.Int)
+	for _, c := range p {
+		content.GCD(nil, nil, content, new(big.Int).Abs(c))
+	}
+	result := make(Polynomial, len(p))
+	for i, c := range p {
+		result[i] = new(big.Int).Quo(c, content)
+	}
+	return result
+}
+
+// trim returns the polynomial without its leading zeros, empty for the zero polynomial.
+func trim(p Polynomial) Polynomial {
+	for len(p) > 0 && p[0].Sign() == 0 {
+		p = p[1:]
+	}
+	return p
+}
+
+// quoPolynomial returns the quotient of the polynomial divided by a monic one.
+func quoPolynomial(p, d Polynomial) Polynomial {
+	r := make(Polynomial, len(p))
+	for i, c := range p {
+		r[i] = new(big.Int).Set(c)
+	}
+	q := make(Polynomial, len(p)-len(d)+1)
+	tmp := new(big.Int)
+	for i := range q {
+		q[i] = new(big.Int).Set(r[i])
+		for j := 1; j < len(d); j++ {
+			r[i+j].Sub(r[i+j], tmp.Mul(q[i], d[j]))
+		}
+	}
+	return q
+}
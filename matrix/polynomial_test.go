@@ -0,0 +1,158 @@
+package matrix
+
+import (
+	"math/big"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type PolynomialTestSuite struct {
+	suite.Suite
+}
+
+// polynomial returns the polynomial of the int64 coefficients.
+func polynomial(coefficients ...int64) Polynomial {
+	p := make(Polynomial, len(coefficients))
+	for i, c := range coefficients {
+		p[i] = big.NewInt(c)
+	}
+	return p
+}
+
+// evaluate returns the value of the polynomial at the matrix.
+func evaluate(p Polynomial, m *Matrix) [][]*big.Int {
+	a := m.bigValues()
+	result := m.Power(0, nil)
+	for i := range result {
+		result[i][i].SetInt64(0)
+	}
+	// Horner's method: result = result*A + c
+	for _, c := range p {
+		result = multiplyBig(result, a, nil)
+		for i := range result {
+			result[i][i].Add(result[i][i], c)
+		}
+	}
+	return result
+}
+
+// Test for Polynomial.String
+func (s *PolynomialTestSuite) TestString() {
+	tests := []struct {
+		p        Polynomial
+		expected string
+	}{
+		{polynomial(1, -15, -18, 0), "x^3 - 15x^2 - 18x"},
+		{polynomial(1, 0, 1), "x^2 + 1"},
+		{polynomial(-1, 1, -1), "-x^2 + x - 1"},
+		{polynomial(2, 0), "2x"},
+		{polynomial(1), "1"},
+		{polynomial(-7), "-7"},
+		{polynomial(0), "0"},
+	}
+
+	for _, tc := range tests {
+		s.Equal(tc.expected, tc.p.String())
+	}
+}
+
+// Test for CharacteristicPolynomial and MinimalPolynomial
+func (s *PolynomialTestSuite) TestPolynomials() {
+	tests := []struct {
+		name                   string
+		values                 [][]int64
+		expectedCharacteristic string
+		expectedMinimal        string
+	}{
+		{
+			name:                   "1*1",
+			values:                 [][]int64{{-7}},
+			expectedCharacteristic: "x + 7",
+			expectedMinimal:        "x + 7",
+		},
+		{
+			name:                   "2*2",
+			values:                 [][]int64{{1, 2}, {3, 4}},
+			expectedCharacteristic: "x^2 - 5x - 2",
+			expectedMinimal:        "x^2 - 5x - 2",
+		},
+		{
+			name:                   "singular 3*3",
+			values:                 [][]int64{{1, 2, 3}, {4, 5, 6}, {7, 8, 9}},
+			expectedCharacteristic: "x^3 - 15x^2 - 18x",
+			expectedMinimal:        "x^3 - 15x^2 - 18x",
+		},
+		{
+			name:                   "identity",
+			values:                 [][]int64{{1, 0, 0}, {0, 1, 0}, {0, 0, 1}},
+			expectedCharacteristic: "x^3 - 3x^2 + 3x - 1",
+			expectedMinimal:        "x - 1",
+		},
+		{
+			name:                   "zero",
+			values:                 [][]int64{{0, 0}, {0, 0}},
+			expectedCharacteristic: "x^2",
+			expectedMinimal:        "x",
+		},
+		{
+			name:                   "jordan block and a repeated eigenvalue",
+			values:                 [][]int64{{2, 1, 0, 0}, {0, 2, 0, 0}, {0, 0, 2, 0}, {0, 0, 0, 3}},
+			expectedCharacteristic: "x^4 - 9x^3 + 30x^2 - 44x + 24",
+			expectedMinimal:        "x^3 - 7x^2 + 16x - 12",
+		},
+		{
+			name:                   "rotation",
+			values:                 [][]int64{{0, -1}, {1, 0}},
+			expectedCharacteristic: "x^2 + 1",
+			expectedMinimal:        "x^2 + 1",
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			m := &Matrix{Values: tc.values}
+			s.Equal(tc.expectedCharacteristic, m.CharacteristicPolynomial().String())
+			s.Equal(tc.expectedMinimal, m.MinimalPolynomial().String())
+		})
+	}
+}
+
+// Test the Cayley-Hamilton theorem and the trace and determinant coefficients on random matrices
+func (s *PolynomialTestSuite) TestRandomMatrices() {
+	rng := rand.New(rand.NewPCG(1, 2))
+	for size := 1; size <= 8; size++ {
+		values := make([][]int64, size)
+		for i := range values {
+			values[i] = make([]int64, size)
+			for j := range values[i] {
+				values[i][j] = rng.Int64N(21) - 10
+			}
+		}
+		m := &Matrix{Values: values}
+
+		p := m.CharacteristicPolynomial()
+		s.Require().Equal(size, p.Degree())
+		s.Equal(int64(1), p[0].Int64())
+		s.Equal(new(big.Int).Neg(m.Trace()).String(), p[1].String())
+		det := m.Determinant()
+		if size%2 == 1 {
+			det.Neg(det)
+		}
+		s.Equal(det.String(), p[size].String())
+
+		for _, poly := range []Polynomial{p, m.MinimalPolynomial()} {
+			for _, row := range evaluate(poly, m) {
+				for _, x := range row {
+					s.Zero(x.Sign(), "%v is not zero at the matrix", poly)
+				}
+			}
+		}
+	}
+}
+
+// Run all tests
+func TestPolynomialTestSuite(t *testing.T) {
+	suite.Run(t, new(PolynomialTestSuite))
+}
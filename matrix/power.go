@@ -0,0 +1,85 @@
+package matrix
+
+import (
+	"math"
+	"math/big"
+)
+
+// Power returns the matrix to the power n computed by repeated squaring, with log2(n) squarings
+// and at most as many more products. Unless mod is nil, every value is reduced modulo mod after
+// each product, so that the values stay in [0, mod).
+func (m *Matrix) Power(n uint64, mod *big.Int) [][]*big.Int {
+	size := m.Size()
+	result := make([][]*big.Int, size)
+	for i := range result {
+		result[i] = make([]*big.Int, size)
+		for j := range result[i] {
+			result[i][j] = new(big.Int)
+		}
+		result[i][i].SetInt64(1)
+	}
+	base := m.bigValues()
+	if mod != nil {
+		reduce(result, mod)
+		reduce(base, mod)
+	}
+
+	// Multiply the result by the squares of the matrix that match the set bits of n
+	for ; n > 0; n >>= 1 {
+		if n&1 == 1 {
+			result = multiplyBig(result, base, mod)
+		}
+		if n > 1 {
+			base = multiplyBig(base, base, mod)
+		}
+	}
+	return result
+}
+
+// PowerBits returns an upper bound of the bits of the values of the matrix to the power n, from
+// |A^n| <= (size*max|A|)^n.
+func (m *Matrix) PowerBits(n uint64) float64 {
+	largest := 0.0
+	for _, row := range m.Values {
+		for _, num := range row {
+			largest = max(largest, math.Abs(float64(num)))
+		}
+	}
+	growth := float64(m.Size()) * largest
+	if n == 0 || growth <= 1 {
+		return 1
+	}
+	return float64(n) * math.Log2(growth)
+}
+
+// multiplyBig returns the product of the square matrices, reduced modulo mod unless it is nil.
+func multiplyBig(a, b [][]*big.Int, mod *big.Int) [][]*big.Int {
+	n := len(a)
+	product := make([][]*big.Int, n)
+	term := new(big.Int)
+	for i := range n {
+		product[i] = make([]*big.Int, n)
+		for j := range n {
+			sum := new(big.Int)
+			for k := range n {
+				if a[i][k].Sign() != 0 && b[k][j].Sign() != 0 {
+					sum.Add(sum, term.Mul(a[i][k], b[k][j]))
+				}
+			}
+			product[i][j] = sum
+		}
+	}
+	if mod != nil {
+		reduce(product, mod)
+	}
+	return product
+}
+
+// reduce replaces every value with its remainder modulo mod, in [0, mod).
+func reduce(values [][]*big.Int, mod *big.Int) {
+	for _, row := range values {
+		for _, x := range row {
+			x.Mod(x, mod)
+		}
+	}
+}
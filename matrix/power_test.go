@@ -0,0 +1,112 @@
+package matrix
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type PowerTestSuite struct {
+	suite.Suite
+}
+
+// bigStrings returns the values as decimal strings.
+func bigStrings(values [][]*big.Int) [][]string {
+	result := make([][]string, len(values))
+	for i, row := range values {
+		result[i] = make([]string, len(row))
+		for j, x := range row {
+			result[i][j] = x.String()
+		}
+	}
+	return result
+}
+
+// Test for Power
+func (s *PowerTestSuite) TestPower() {
+	fibonacci := &Matrix{Values: [][]int64{{1, 1}, {1, 0}}}
+	tests := []struct {
+		name     string
+		m        *Matrix
+		n        uint64
+		mod      *big.Int
+		expected [][]string
+	}{
+		{
+			name:     "identity for n=0",
+			m:        &Matrix{Values: [][]int64{{1, 2}, {3, 4}}},
+			n:        0,
+			expected: [][]string{{"1", "0"}, {"0", "1"}},
+		},
+		{
+			name:     "n=1",
+			m:        &Matrix{Values: [][]int64{{1, -2}, {3, 4}}},
+			n:        1,
+			expected: [][]string{{"1", "-2"}, {"3", "4"}},
+		},
+		{
+			name:     "square",
+			m:        &Matrix{Values: [][]int64{{1, 2, 3}, {4, 5, 6}, {7, 8, 9}}},
+			n:        2,
+			expected: [][]string{{"30", "36", "42"}, {"66", "81", "96"}, {"102", "126", "150"}},
+		},
+		{
+			name: "fibonacci numbers",
+			m:    fibonacci,
+			n:    100,
+			expected: [][]string{
+				{"573147844013817084101", "354224848179261915075"},
+				{"354224848179261915075", "218922995834555169026"},
+			},
+		},
+		{
+			name:     "fibonacci numbers modulo a prime",
+			m:        fibonacci,
+			n:        100,
+			mod:      big.NewInt(1000000007),
+			expected: [][]string{{"782204094", "687995182"}, {"687995182", "94208912"}},
+		},
+		{
+			name:     "negative values are reduced into [0, mod)",
+			m:        &Matrix{Values: [][]int64{{-1, 0}, {0, 2}}},
+			n:        3,
+			mod:      big.NewInt(5),
+			expected: [][]string{{"4", "0"}, {"0", "3"}},
+		},
+		{
+			name:     "identity modulo mod for n=0",
+			m:        &Matrix{Values: [][]int64{{7}}},
+			n:        0,
+			mod:      big.NewInt(3),
+			expected: [][]string{{"1"}},
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			s.Equal(tc.expected, bigStrings(tc.m.Power(tc.n, tc.mod)))
+		})
+	}
+}
+
+// Test that a huge exponent takes as many steps as its bits
+func (s *PowerTestSuite) TestHugeExponent() {
+	// The permutation matrix of a 3-cycle has order 3, and 2^64-1 is a multiple of 3
+	cycle := &Matrix{Values: [][]int64{{0, 1, 0}, {0, 0, 1}, {1, 0, 0}}}
+	s.Equal([][]string{{"1", "0", "0"}, {"0", "1", "0"}, {"0", "0", "1"}}, bigStrings(cycle.Power(1<<64-1, big.NewInt(1000000007))))
+}
+
+// Test for PowerBits
+func (s *PowerTestSuite) TestPowerBits() {
+	s.InDelta(1000.0, (&Matrix{Values: [][]int64{{1, 1}, {1, 0}}}).PowerBits(1000), 1e-9)
+	s.InDelta(2*10.0, (&Matrix{Values: [][]int64{{-512, 0}, {0, 1}}}).PowerBits(2), 1e-9)
+	s.Equal(1.0, (&Matrix{Values: [][]int64{{1}}}).PowerBits(1<<60))
+	s.Equal(1.0, (&Matrix{Values: [][]int64{{5}}}).PowerBits(0))
+	s.Equal(1.0, (&Matrix{Values: [][]int64{{0, 0}, {0, 0}}}).PowerBits(10))
+}
+
+// Run all tests
+func TestPowerTestSuite(t *testing.T) {
+	suite.Run(t, new(PowerTestSuite))
+}
@@ -0,0 +1,168 @@
+package matrix
+
+import (
+	"math"
+	"math/big"
+)
+
+// Properties are the invariants and structural properties of a matrix.
+type Properties struct {
+	Trace       *big.Int
+	Rank        int
+	Determinant *big.Int
+
+	Symmetric       bool
+	SkewSymmetric   bool
+	Diagonal        bool
+	UpperTriangular bool
+	LowerTriangular bool
+	Identity        bool
+	// Orthogonal is set when the matrix times its transpose is the identity, which for
+	// integers is a permutation matrix with signs
+	Orthogonal  bool
+	Permutation bool
+}
+
+// Properties returns the properties of the matrix, computed with exact integer arithmetic.
+func (m *Matrix) Properties() Properties {
+	rank, det := m.eliminate()
+	p := Properties{
+		Trace:           m.Trace(),
+		Rank:            rank,
+		Determinant:     det,
+		Symmetric:       true,
+		SkewSymmetric:   true,
+		UpperTriangular: true,
+		LowerTriangular: true,
+	}
+
+	for i, row := range m.Values {
+		for j, num := range row {
+			switch {
+			case i > j && num != 0:
+				p.UpperTriangular = false
+			case i < j && num != 0:
+				p.LowerTriangular = false
+			}
+			if num != m.Values[j][i] {
+				p.Symmetric = false
+			}
+			if num != -m.Values[j][i] || num == math.MinInt64 {
+				p.SkewSymmetric = false
+			}
+		}
+	}
+	p.Diagonal = p.UpperTriangular && p.LowerTriangular
+	p.Permutation = m.isSignedPermutation(false)
+	p.Identity = p.Diagonal && p.Permutation
+	p.Orthogonal = m.isSignedPermutation(true)
+	return p
+}
+
+// Trace returns the sum of the diagonal of the matrix.
+func (m *Matrix) Trace() *big.Int {
+	trace := new(big.Int)
+	for i, row := range m.Values {
+		trace.Add(trace, big.NewInt(row[i]))
+	}
+	return trace
+}
+
+// Determinant returns the determinant of the matrix.
+func (m *Matrix) Determinant() *big.Int {
+	_, det := m.eliminate()
+	return det
+}
+
+// Rank returns the number of linearly independent rows of the matrix.
+func (m *Matrix) Rank() int {
+	rank, _ := m.eliminate()
+	return rank
+}
+
+// isSignedPermutation reports whether every row and column has a single nonzero value, which
+// is 1, or 1 or -1 when signed is set.
+func (m *Matrix) isSignedPermutation(signed bool) bool {
+	colCounts := make([]int, m.Size())
+	for _, row := range m.Values {
+		rowCount := 0
+		for j, num := range row {
+			switch {
+			case num == 0:
+				continue
+			case num == 1, signed && num == -1:
+				rowCount++
+				colCounts[j]++
+			default:
+				return false
+			}
+		}
+		if rowCount != 1 {
+			return false
+		}
+	}
+	for _, count := range colCounts {
+		if count != 1 {
+			return false
+		}
+	}
+	return true
+}
+
+// eliminate reduces a copy of the matrix to row echelon form with the fraction-free Bareiss
+// algorithm, where every division is exact, and returns its rank and determinant.
+func (m *Matrix) eliminate() (int, *big.Int) {
+	n := m.Size()
+	a := m.bigValues()
+
+	sign, rank := 1, 0
+	prev := big.NewInt(1)
+	tmp := new(big.Int)
+	for col := 0; col < n && rank < n; col++ {
+		// 1st Step: find a nonzero pivot in the column and swap it into place
+		pivot := rank
+		for pivot < n && a[pivot][col].Sign() == 0 {
+			pivot++
+		}
+		if pivot == n {
+			continue
+		}
+		if pivot != rank {
+			a[pivot], a[rank] = a[rank], a[pivot]
+			sign = -sign
+		}
+
+		// 2nd Step: eliminate the column below the pivot
+		for i := rank + 1; i < n; i++ {
+			for j := col + 1; j < n; j++ {
+				a[i][j].Mul(a[i][j], a[rank][col])
+				a[i][j].Sub(a[i][j], tmp.Mul(a[i][col], a[rank][j]))
+				a[i][j].Quo(a[i][j], prev)
+			}
+			a[i][col].SetInt64(0)
+		}
+		prev = a[rank][col]
+		rank++
+	}
+
+	if rank < n {
+		return rank, new(big.Int)
+	}
+	det := new(big.Int).Set(a[n-1][n-1])
+	if sign < 0 {
+		det.Neg(det)
+	}
+	return rank, det
+}
+
+// bigValues returns a copy of the values as big integers.
+func (m *Matrix) bigValues() [][]*big.Int {
+	values := make([][]*big.Int, len(m.Values))
+	for i, row := range m.Values {
+		values[i] = make([]*big.Int, len(row))
+		for j, num := range row {
+			values[i][j] = big.NewInt(num)
+		}
+	}
+	return values
+}
@@ -0,0 +1,148 @@
+package matrix
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type PropertiesTestSuite struct {
+	suite.Suite
+}
+
+// Test for the rank and determinant
+func (s *PropertiesTestSuite) TestRankAndDeterminant() {
+	tests := []struct {
+		name                string
+		values              [][]int64
+		expectedRank        int
+		expectedDeterminant string
+	}{
+		{
+			name:                "1*1 matrix",
+			values:              [][]int64{{-7}},
+			expectedRank:        1,
+			expectedDeterminant: "-7",
+		},
+		{
+			name:                "zero matrix",
+			values:              [][]int64{{0, 0}, {0, 0}},
+			expectedRank:        0,
+			expectedDeterminant: "0",
+		},
+		{
+			name:                "singular 3*3 matrix",
+			values:              [][]int64{{1, 2, 3}, {4, 5, 6}, {7, 8, 9}},
+			expectedRank:        2,
+			expectedDeterminant: "0",
+		},
+		{
+			name:                "pivot in a later row",
+			values:              [][]int64{{0, 1}, {1, 0}},
+			expectedRank:        2,
+			expectedDeterminant: "-1",
+		},
+		{
+			name:                "rank deficient with a zero column",
+			values:              [][]int64{{0, 1, 2}, {0, 2, 4}, {0, 3, 7}},
+			expectedRank:        2,
+			expectedDeterminant: "0",
+		},
+		{
+			name:                "4*4 matrix",
+			values:              [][]int64{{1, 2, 3, 4}, {2, 2, -1, -10}, {3, 3, 5, -2}, {4, 3, 2, 1}},
+			expectedRank:        4,
+			expectedDeterminant: "-195",
+		},
+		{
+			name:                "determinant beyond int64",
+			values:              [][]int64{{math.MaxInt64, 0}, {0, math.MaxInt64}},
+			expectedRank:        2,
+			expectedDeterminant: "85070591730234615847396907784232501249",
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			m := &Matrix{Values: tc.values}
+			s.Equal(tc.expectedRank, m.Rank())
+			s.Equal(tc.expectedDeterminant, m.Determinant().String())
+		})
+	}
+}
+
+// Test for the structural properties
+func (s *PropertiesTestSuite) TestProperties() {
+	tests := []struct {
+		name     string
+		values   [][]int64
+		expected Properties
+	}{
+		{
+			name:   "identity",
+			values: [][]int64{{1, 0, 0}, {0, 1, 0}, {0, 0, 1}},
+			expected: Properties{Symmetric: true, Diagonal: true, UpperTriangular: true, LowerTriangular: true,
+				Identity: true, Orthogonal: true, Permutation: true},
+		},
+		{
+			name:     "permutation",
+			values:   [][]int64{{0, 1, 0}, {0, 0, 1}, {1, 0, 0}},
+			expected: Properties{Orthogonal: true, Permutation: true},
+		},
+		{
+			name:     "signed permutation",
+			values:   [][]int64{{0, -1}, {1, 0}},
+			expected: Properties{SkewSymmetric: true, Orthogonal: true},
+		},
+		{
+			name:     "symmetric",
+			values:   [][]int64{{2, 3}, {3, 5}},
+			expected: Properties{Symmetric: true},
+		},
+		{
+			name:     "diagonal",
+			values:   [][]int64{{2, 0}, {0, 5}},
+			expected: Properties{Symmetric: true, Diagonal: true, UpperTriangular: true, LowerTriangular: true},
+		},
+		{
+			name:     "upper triangular",
+			values:   [][]int64{{1, 2}, {0, 3}},
+			expected: Properties{UpperTriangular: true},
+		},
+		{
+			name:     "lower triangular",
+			values:   [][]int64{{1, 0}, {2, 3}},
+			expected: Properties{LowerTriangular: true},
+		},
+		{
+			name:     "zero is symmetric and skew-symmetric",
+			values:   [][]int64{{0, 0}, {0, 0}},
+			expected: Properties{Symmetric: true, SkewSymmetric: true, Diagonal: true, UpperTriangular: true, LowerTriangular: true},
+		},
+		{
+			name:     "minimum int64 is not its own negation",
+			values:   [][]int64{{0, math.MinInt64}, {math.MinInt64, 0}},
+			expected: Properties{Symmetric: true},
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			p := (&Matrix{Values: tc.values}).Properties()
+			p.Trace, p.Rank, p.Determinant = nil, 0, nil
+			s.Equal(tc.expected, p)
+		})
+	}
+}
+
+// Test for Trace
+func (s *PropertiesTestSuite) TestTrace() {
+	s.Equal("15", (&Matrix{Values: [][]int64{{1, 2, 3}, {4, 5, 6}, {7, 8, 9}}}).Trace().String())
+	s.Equal("18446744073709551614", (&Matrix{Values: [][]int64{{math.MaxInt64, 0}, {0, math.MaxInt64}}}).Trace().String())
+}
+
+// Run all tests
+func TestPropertiesTestSuite(t *testing.T) {
+	suite.Run(t, new(PropertiesTestSuite))
+}
@@ -0,0 +1,223 @@
+package matrix
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+)
+
+// Kinds of elementary row operations.
+const (
+	RowSwap  = "swap"
+	RowScale = "scale"
+	RowAdd   = "add"
+)
+
+// RowOperation is an elementary row operation of a Gauss-Jordan elimination. Rows are numbered
+// from 0.
+type RowOperation struct {
+	// Kind is RowSwap, RowScale or RowAdd
+	Kind string
+	// Row is the row that changes, swapped with Source, multiplied by Factor, or added Factor
+	// times the Source row
+	Row    int
+	Source int
+	Factor *big.Rat
+	// Result is the matrix after the operation
+	Result [][]*big.Rat
+}
+
+// String returns the operation in the notation of linear algebra courses, with rows numbered
+// from 1, such as "R2 <- R2 - 4*R1".
+func (op RowOperation) String() string {
+	switch op.Kind {
+	case RowSwap:
+		return fmt.Sprintf("R%d <-> R%d", op.Row+1, op.Source+1)
+	case RowScale:
+		return fmt.Sprintf("R%d <- %s*R%d", op.Row+1, op.Factor.RatString(), op.Row+1)
+	}
+	sign, factor := "+", new(big.Rat).Set(op.Factor)
+	if factor.Sign() < 0 {
+		sign = "-"
+		factor.Neg(factor)
+	}
+	if factor.Cmp(big.NewRat(1, 1)) == 0 {
+		return fmt.Sprintf("R%d <- R%d %s R%d", op.Row+1, op.Row+1, sign, op.Source+1)
+	}
+	return fmt.Sprintf("R%d <- R%d %s %s*R%d", op.Row+1, op.Row+1, sign, factor.RatString(), op.Source+1)
+}
+
+// RowEchelon is the reduced row echelon form of a matrix.
+type RowEchelon struct {
+	Values [][]*big.Rat
+	// Pivots are the columns of the leading 1 of the nonzero rows, numbered from 0
+	Pivots []int
+	// Steps are the row operations in order, recorded when requested
+	Steps []RowOperation
+}
+
+// Rank returns the number of nonzero rows.
+func (e *RowEchelon) Rank() int {
+	return len(e.Pivots)
+}
+
+// RREF returns the reduced row echelon form of the matrix with exact rational values. The first
+// nonzero value of a column is its pivot, as done by hand, and every row operation of the
+// Gauss-Jordan elimination is recorded when steps is set.
+func (m *Matrix) RREF(steps bool) *RowEchelon {
+	e, _ := m.RREFContext(context.Background(), steps)
+	return e
+}
+
+// RREFContext is RREF for long eliminations: it checks ctx between pivots and returns its error
+// once it is done. Without steps the elimination is fraction-free, so the values stay integers
+// no larger than the minors of the matrix, and they are only divided by the pivots at the end.
+func (m *Matrix) RREFContext(ctx context.Context, steps bool) (*RowEchelon, error) {
+	if steps {
+		return m.gaussJordan(ctx)
+	}
+	return m.fractionFreeRREF(ctx)
+}
+
+// fractionFreeRREF reduces a copy of the matrix with the Bareiss variant of Gauss-Jordan
+// elimination, where every division by the previous pivot is exact, and then divides each
+// nonzero row by its pivot.
+func (m *Matrix) fractionFreeRREF(ctx context.Context) (*RowEchelon, error) {
+	n := m.Size()
+	a := m.bigValues()
+
+	var pivots []int
+	prev := big.NewInt(1)
+	tmp := new(big.Int)
+	row := 0
+	for col := 0; col < n && row < n; col++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		// 1st Step: move the first row with a nonzero value in the column up
+		pivot := row
+		for pivot < n && a[pivot][col].Sign() == 0 {
+			pivot++
+		}
+		if pivot == n {
+			continue
+		}
+		a[pivot], a[row] = a[row], a[pivot]
+
+		// 2nd Step: clear the column in every other row, which also scales the earlier pivots
+		// to the new one
+		for i := range n {
+			if i == row {
+				continue
+			}
+			for j := range n {
+				if j == col {
+					continue
+				}
+				a[i][j].Mul(a[i][j], a[row][col])
+				a[i][j].Sub(a[i][j], tmp.Mul(a[i][col], a[row][j]))
+				a[i][j].Quo(a[i][j], prev)
+			}
+			a[i][col].SetInt64(0)
+		}
+		// The pivot changes with the later steps, so keep its value
+		prev = new(big.Int).Set(a[row][col])
+		pivots = append(pivots, col)
+		row++
+	}
+
+	// 3rd Step: divide every nonzero row by its pivot
+	values := make([][]*big.Rat, n)
+	for i := range values {
+		values[i] = make([]*big.Rat, n)
+		for j := range values[i] {
+			values[i][j] = new(big.Rat)
+			if i < len(pivots) {
+				values[i][j].SetFrac(a[i][j], a[i][pivots[i]])
+			}
+		}
+	}
+	return &RowEchelon{Values: values, Pivots: pivots}, nil
+}
+
+// gaussJordan reduces a copy of the matrix with rational Gauss-Jordan elimination and records
+// every row operation.
+func (m *Matrix) gaussJordan(ctx context.Context) (*RowEchelon, error) {
+	n := m.Size()
+	a := make([][]*big.Rat, n)
+	for i, row := range m.Values {
+		a[i] = make([]*big.Rat, len(row))
+		for j, num := range row {
+			a[i][j] = new(big.Rat).SetInt64(num)
+		}
+	}
+
+	e := &RowEchelon{Values: a}
+	record := func(op RowOperation) {
+		op.Result = copyRats(a)
+		e.Steps = append(e.Steps, op)
+	}
+
+	tmp := new(big.Rat)
+	row := 0
+	for col := 0; col < n && row < n; col++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		// 1st Step: move the first row with a nonzero value in the column up
+		pivot := row
+		for pivot < n && a[pivot][col].Sign() == 0 {
+			pivot++
+		}
+		if pivot == n {
+			continue
+		}
+		if pivot != row {
+			a[pivot], a[row] = a[row], a[pivot]
+			record(RowOperation{Kind: RowSwap, Row: row, Source: pivot})
+		}
+
+		// 2nd Step: scale the row so that the pivot is 1
+		if !isOne(a[row][col]) {
+			factor := new(big.Rat).Inv(a[row][col])
+			for j := col; j < n; j++ {
+				a[row][j].Mul(a[row][j], factor)
+			}
+			record(RowOperation{Kind: RowScale, Row: row, Factor: factor})
+		}
+
+		// 3rd Step: clear the column in every other row
+		for i := range n {
+			if i == row || a[i][col].Sign() == 0 {
+				continue
+			}
+			factor := new(big.Rat).Neg(a[i][col])
+			for j := col; j < n; j++ {
+				a[i][j].Add(a[i][j], tmp.Mul(factor, a[row][j]))
+			}
+			record(RowOperation{Kind: RowAdd, Row: i, Source: row, Factor: factor})
+		}
+
+		e.Pivots = append(e.Pivots, col)
+		row++
+	}
+	return e, nil
+}
+
+func isOne(r *big.Rat) bool {
+	return r.IsInt() && r.Num().IsInt64() && r.Num().Int64() == 1
+}
+
+// copyRats returns a deep copy of the rows of rationals.
+func copyRats(values [][]*big.Rat) [][]*big.Rat {
+	copied := make([][]*big.Rat, len(values))
+	for i, row := range values {
+		copied[i] = make([]*big.Rat, len(row))
+		for j, r := range row {
+			copied[i][j] = new(big.Rat).Set(r)
+		}
+	}
+	return copied
+}
@@ -0,0 +1,136 @@
+package matrix
+
+import (
+	"context"
+	"math/big"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type RREFTestSuite struct {
+	suite.Suite
+}
+
+// ratStrings returns the rows of fractions as strings such as "-1/2".
+func ratStrings(values [][]*big.Rat) [][]string {
+	rows := make([][]string, len(values))
+	for i, row := range values {
+		for _, r := range row {
+			rows[i] = append(rows[i], r.RatString())
+		}
+	}
+	return rows
+}
+
+// Test for RREF
+func (s *RREFTestSuite) TestRREF() {
+	tests := []struct {
+		name           string
+		values         [][]int64
+		expectedValues [][]string
+		expectedPivots []int
+	}{
+		{
+			name:           "singular 3*3 matrix",
+			values:         [][]int64{{1, 2, 3}, {4, 5, 6}, {7, 8, 9}},
+			expectedValues: [][]string{{"1", "0", "-1"}, {"0", "1", "2"}, {"0", "0", "0"}},
+			expectedPivots: []int{0, 1},
+		},
+		{
+			name:           "invertible matrix",
+			values:         [][]int64{{2, 1}, {4, 3}},
+			expectedValues: [][]string{{"1", "0"}, {"0", "1"}},
+			expectedPivots: []int{0, 1},
+		},
+		{
+			name:           "fractions",
+			values:         [][]int64{{2, 1, 1}, {4, 2, 3}, {0, 0, 0}},
+			expectedValues: [][]string{{"1", "1/2", "0"}, {"0", "0", "1"}, {"0", "0", "0"}},
+			expectedPivots: []int{0, 2},
+		},
+		{
+			name:           "zero matrix",
+			values:         [][]int64{{0, 0}, {0, 0}},
+			expectedValues: [][]string{{"0", "0"}, {"0", "0"}},
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			e := (&Matrix{Values: tc.values}).RREF(false)
+			s.Equal(tc.expectedValues, ratStrings(e.Values))
+			s.Equal(tc.expectedPivots, e.Pivots)
+			s.Equal(len(tc.expectedPivots), e.Rank())
+			s.Empty(e.Steps)
+		})
+	}
+}
+
+// Test for the recorded row operations
+func (s *RREFTestSuite) TestSteps() {
+	m := &Matrix{Values: [][]int64{{0, 2}, {3, 1}}}
+	e := m.RREF(true)
+
+	var descriptions []string
+	for _, op := range e.Steps {
+		descriptions = append(descriptions, op.String())
+	}
+	s.Equal([]string{"R1 <-> R2", "R1 <- 1/3*R1", "R2 <- 1/2*R2", "R1 <- R1 - 1/3*R2"}, descriptions)
+	s.Equal([][]string{{"3", "1"}, {"0", "2"}}, ratStrings(e.Steps[0].Result))
+	s.Equal(ratStrings(e.Values), ratStrings(e.Steps[len(e.Steps)-1].Result))
+	s.Equal([][]int64{{0, 2}, {3, 1}}, m.Values, "the matrix is not changed")
+}
+
+// Test that the fraction-free elimination matches the recorded Gauss-Jordan elimination on
+// random matrices, with repeated rows for the singular ones
+func (s *RREFTestSuite) TestFractionFree() {
+	rng := rand.New(rand.NewPCG(3, 4))
+	for size := 1; size <= 9; size++ {
+		values := make([][]int64, size)
+		for i := range values {
+			values[i] = make([]int64, size)
+			for j := range values[i] {
+				values[i][j] = rng.Int64N(7) - 3
+			}
+		}
+		if size%3 == 0 {
+			copy(values[size-1], values[0])
+			for j := range values[1] {
+				values[1][j] = 2 * values[0][j]
+			}
+		}
+		m := &Matrix{Values: values}
+
+		fast := m.RREF(false)
+		steps := m.RREF(true)
+		s.Equal(ratStrings(steps.Values), ratStrings(fast.Values), "size %d", size)
+		s.Equal(steps.Pivots, fast.Pivots, "size %d", size)
+	}
+}
+
+// Test that a canceled context stops the elimination
+func (s *RREFTestSuite) TestCanceled() {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	m := &Matrix{Values: [][]int64{{1, 2}, {3, 4}}}
+	for _, steps := range []bool{false, true} {
+		_, err := m.RREFContext(ctx, steps)
+		s.ErrorIs(err, context.Canceled)
+	}
+}
+
+// Test for the notation of row operations
+func (s *RREFTestSuite) TestRowOperationString() {
+	s.Equal("R1 <-> R3", RowOperation{Kind: RowSwap, Row: 0, Source: 2}.String())
+	s.Equal("R2 <- -1/3*R2", RowOperation{Kind: RowScale, Row: 1, Factor: big.NewRat(-1, 3)}.String())
+	s.Equal("R2 <- R2 - 4*R1", RowOperation{Kind: RowAdd, Row: 1, Source: 0, Factor: big.NewRat(-4, 1)}.String())
+	s.Equal("R3 <- R3 + R2", RowOperation{Kind: RowAdd, Row: 2, Source: 1, Factor: big.NewRat(1, 1)}.String())
+	s.Equal("R1 <- R1 - R2", RowOperation{Kind: RowAdd, Row: 0, Source: 1, Factor: big.NewRat(-1, 1)}.String())
+}
+
+// Run all tests
+func TestRREFTestSuite(t *testing.T) {
+	suite.Run(t, new(RREFTestSuite))
+}
@@ -0,0 +1,153 @@
+package matrix
+
+import (
+	"cmp"
+	"fmt"
+	"math/big"
+	"slices"
+)
+
+// Entry is a nonzero value of a Sparse matrix, at a 0-based row and column.
+type Entry struct {
+	Row   int
+	Col   int
+	Value int64
+}
+
+// Sparse is a square matrix stored as its nonzero entries, for large mostly-zero matrices.
+// Entries are sorted by row then column, without duplicates and without zeros.
+type Sparse struct {
+	N       int
+	Entries []Entry
+}
+
+// NewSparse returns an N*N Sparse matrix of the entries, or an error if an entry is outside
+// the matrix or set twice. Zero entries are dropped.
+func NewSparse(n int, entries []Entry) (*Sparse, error) {
+	if n <= 0 {
+		return nil, fmt.Errorf("empty matrix")
+	}
+
+	sorted := make([]Entry, 0, len(entries))
+	for _, e := range entries {
+		if e.Row < 0 || e.Row >= n || e.Col < 0 || e.Col >= n {
+			return nil, fmt.Errorf("matrix entry at row %d, column %d is outside the %dx%d matrix", e.Row+1, e.Col+1, n, n)
+		}
+		if e.Value != 0 {
+			sorted = append(sorted, e)
+		}
+	}
+	slices.SortFunc(sorted, compareEntries)
+	for i := 1; i < len(sorted); i++ {
+		if compareEntries(sorted[i-1], sorted[i]) == 0 {
+			return nil, fmt.Errorf("matrix has duplicate entry at row %d, column %d", sorted[i].Row+1, sorted[i].Col+1)
+		}
+	}
+
+	return &Sparse{N: n, Entries: sorted}, nil
+}
+
+func compareEntries(a, b Entry) int {
+	return cmp.Or(cmp.Compare(a.Row, b.Row), cmp.Compare(a.Col, b.Col))
+}
+
+// Size returns the number of rows, which is also the number of columns.
+func (s *Sparse) Size() int {
+	return s.N
+}
+
+// Rows calls fn with every row of the matrix in order, reusing the same slice for every row.
+// It stops when fn returns false.
+func (s *Sparse) Rows(fn func(i int, row []int64) bool) {
+	row := make([]int64, s.N)
+	next := 0
+	for i := range s.N {
+		clear(row)
+		for ; next < len(s.Entries) && s.Entries[next].Row == i; next++ {
+			row[s.Entries[next].Col] = s.Entries[next].Value
+		}
+		if !fn(i, row) {
+			return
+		}
+	}
+}
+
+// Transpose returns the matrix where the columns and rows are inverted.
+func (s *Sparse) Transpose() *Sparse {
+	entries := make([]Entry, len(s.Entries))
+	for i, e := range s.Entries {
+		entries[i] = Entry{Row: e.Col, Col: e.Row, Value: e.Value}
+	}
+	slices.SortFunc(entries, compareEntries)
+	return &Sparse{N: s.N, Entries: entries}
+}
+
+// Sum returns the sum of the integers in the matrix.
+func (s *Sparse) Sum() *big.Int {
+	sum := new(big.Int)
+	for _, e := range s.Entries {
+		sum.Add(sum, big.NewInt(e.Value))
+	}
+	return sum
+}
+
+// Product returns the product of the integers in the matrix, which is 0 unless every value is set.
+func (s *Sparse) Product() *big.Int {
+	if len(s.Entries) < s.N*s.N {
+		return big.NewInt(0)
+	}
+	product := big.NewInt(1)
+	for _, e := range s.Entries {
+		product.Mul(product, big.NewInt(e.Value))
+	}
+	return product
+}
+
+// SumMod returns the sum of the integers in the matrix modulo mod, in [0, mod).
+func (s *Sparse) SumMod(mod *big.Int) *big.Int {
+	sum := new(big.Int)
+	for _, e := range s.Entries {
+		sum.Add(sum, big.NewInt(e.Value))
+		sum.Mod(sum, mod)
+	}
+	return sum
+}
+
+// ProductMod returns the product of the integers in the matrix modulo mod, in [0, mod), which
+// is 0 unless every value is set.
+func (s *Sparse) ProductMod(mod *big.Int) *big.Int {
+	if len(s.Entries) < s.N*s.N {
+		return big.NewInt(0)
+	}
+	product := new(big.Int).Mod(big.NewInt(1), mod)
+	for _, e := range s.Entries {
+		product.Mul(product, big.NewInt(e.Value))
+		product.Mod(product, mod)
+	}
+	return product
+}
+
+// Dense returns the matrix with every value stored.
+func (s *Sparse) Dense() *Matrix {
+	values := make([][]int64, s.N)
+	for i := range values {
+		values[i] = make([]int64, s.N)
+	}
+	for _, e := range s.Entries {
+		values[e.Row][e.Col] = e.Value
+	}
+	return &Matrix{Values: values}
+}
+
+// Sparse returns the nonzero entries of the matrix.
+func (m *Matrix) Sparse() *Sparse {
+	var entries []Entry
+	for i, row := range m.Values {
+		for j, num := range row {
+			if num != 0 {
+				entries = append(entries, Entry{Row: i, Col: j, Value: num})
+			}
+		}
+	}
+	return &Sparse{N: len(m.Values), Entries: entries}
+}
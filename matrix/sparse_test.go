@@ -0,0 +1,66 @@
+package matrix
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type SparseTestSuite struct {
+	suite.Suite
+}
+
+// Test for NewSparse
+func (s *SparseTestSuite) TestNewSparse() {
+	sparse, err := NewSparse(3, []Entry{{Row: 2, Col: 0, Value: 4}, {Row: 0, Col: 1, Value: 0}, {Row: 0, Col: 2, Value: 1}})
+	s.NoError(err)
+	s.Equal([]Entry{{Row: 0, Col: 2, Value: 1}, {Row: 2, Col: 0, Value: 4}}, sparse.Entries)
+
+	_, err = NewSparse(0, nil)
+	s.EqualError(err, "empty matrix")
+
+	_, err = NewSparse(2, []Entry{{Row: 2, Col: 0, Value: 1}})
+	s.EqualError(err, "matrix entry at row 3, column 1 is outside the 2x2 matrix")
+}
+
+// Test that sparse operations match the dense ones
+func (s *SparseTestSuite) TestOperations() {
+	tests := []struct {
+		name   string
+		values [][]int64
+	}{
+		{name: "mostly zero", values: [][]int64{{0, 0, 3}, {0, 0, 0}, {-2, 0, 5}}},
+		{name: "all zero", values: [][]int64{{0, 0}, {0, 0}}},
+		{name: "no zero", values: [][]int64{{1, 2}, {3, -4}}},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			m, err := New(tc.values)
+			s.Require().NoError(err)
+			sparse := m.Sparse()
+
+			s.Equal(m.Size(), sparse.Size())
+			s.Equal(m.Values, sparse.Dense().Values)
+			s.Equal(m.Transpose().Values, sparse.Transpose().Dense().Values)
+			s.Equal(m.Sum().String(), sparse.Sum().String())
+			s.Equal(m.Product().String(), sparse.Product().String())
+			s.Equal(m.SumMod(big.NewInt(7)).String(), sparse.SumMod(big.NewInt(7)).String())
+			s.Equal(m.ProductMod(big.NewInt(7)).String(), sparse.ProductMod(big.NewInt(7)).String())
+
+			var rows [][]int64
+			sparse.Rows(func(i int, row []int64) bool {
+				s.Equal(len(rows), i)
+				rows = append(rows, append([]int64(nil), row...))
+				return true
+			})
+			s.Equal(m.Values, rows)
+		})
+	}
+}
+
+// Run all tests
+func TestSparseTestSuite(t *testing.T) {
+	suite.Run(t, new(SparseTestSuite))
+}
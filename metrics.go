@@ -0,0 +1,9 @@
+package main
+
+import "expvar"
+
+// Server metrics, published as JSON at /debug/vars.
+var (
+	panicsTotal  = expvar.NewInt("panics_total")
+	panicsByPath = expvar.NewMap("panics_by_path")
+)
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"time"
+)
+
+// RequestIDHeader is the header used to receive and return the request ID.
+const RequestIDHeader = "X-Request-ID"
+
+type requestInfoKey struct{}
+
+// requestInfo holds the per-request details collected by handlers for the request log.
+type requestInfo struct {
+	ID      string
+	Client  string
+	KeyID   string
+	Rows    int
+	Cols    int
+	Failure string
+}
+
+// getRequestInfo returns the request info attached by LoggingMiddleware, or nil if there is none.
+func getRequestInfo(r *http.Request) *requestInfo {
+	info, _ := r.Context().Value(requestInfoKey{}).(*requestInfo)
+	return info
+}
+
+// RequestID returns the ID of the request, or an empty string if it was not assigned one.
+func RequestID(r *http.Request) string {
+	if info := getRequestInfo(r); info != nil {
+		return info.ID
+	}
+	return ""
+}
+
+// recordMatrixSize stores the dimensions of the parsed matrix for the request log.
+func recordMatrixSize(r *http.Request, rows int, cols int) {
+	if info := getRequestInfo(r); info != nil {
+		info.Rows = rows
+		info.Cols = cols
+	}
+}
+
+// ProblemContentType is the content type of error responses for clients that accept it (RFC 9457).
+const ProblemContentType = "application/problem+json"
+
+// Problem is the structured error body sent to clients that accept ProblemContentType.
+type Problem struct {
+	Title     string `json:"title"`
+	Status    int    `json:"status"`
+	Detail    string `json:"detail"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// writeError replies with the error message and the request ID, and records the message
+// as the failure reason for the request log. Clients accepting ProblemContentType get a
+// Problem instead of plain text.
+func writeError(w http.ResponseWriter, r *http.Request, message string, code int) {
+	id := ""
+	if info := getRequestInfo(r); info != nil {
+		info.Failure = message
+		id = info.ID
+	}
+
+	if acceptQuality(r.Header.Get("Accept"), ProblemContentType) > 0 {
+		w.Header().Set("Content-Type", ProblemContentType)
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.WriteHeader(code)
+		json.NewEncoder(w).Encode(Problem{
+			Title:     http.StatusText(code),
+			Status:    code,
+			Detail:    message,
+			RequestID: id,
+		})
+		return
+	}
+
+	if id != "" {
+		message = fmt.Sprintf("%s (request id: %s)", message, id)
+	}
+	http.Error(w, message, code)
+}
+
+// statusRecorder captures the status code and body size written by a handler.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (sr *statusRecorder) WriteHeader(code int) {
+	if sr.status == 0 {
+		sr.status = code
+	}
+	sr.ResponseWriter.WriteHeader(code)
+}
+
+func (sr *statusRecorder) Write(b []byte) (int, error) {
+	if sr.status == 0 {
+		sr.status = http.StatusOK
+	}
+	n, err := sr.ResponseWriter.Write(b)
+	sr.bytes += n
+	return n, err
+}
+
+// LoggingMiddleware assigns every request an ID and logs one structured line per request
+// with method, path, status, duration, matrix dimensions and validation failure reason.
+func LoggingMiddleware(logger *slog.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		// Reuse the caller's request ID so logs can be correlated across services
+		id := r.Header.Get(RequestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		w.Header().Set(RequestIDHeader, id)
+
+		info := &requestInfo{ID: id}
+		r = r.WithContext(context.WithValue(r.Context(), requestInfoKey{}, info))
+		rec := &statusRecorder{ResponseWriter: w}
+
+		next.ServeHTTP(rec, r)
+
+		if rec.status == 0 {
+			rec.status = http.StatusOK
+		}
+		attrs := []slog.Attr{
+			slog.String("request_id", id),
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.Int("status", rec.status),
+			slog.Duration("duration", time.Since(start)),
+			slog.Int("bytes", rec.bytes),
+		}
+		if info.Client != "" {
+			attrs = append(attrs, slog.String("client", info.Client))
+		}
+		if info.Rows > 0 {
+			attrs = append(attrs, slog.Int("rows", info.Rows), slog.Int("cols", info.Cols))
+		}
+		if info.Failure != "" {
+			attrs = append(attrs, slog.String("error", info.Failure))
+		}
+
+		level := slog.LevelInfo
+		switch {
+		case rec.status >= 500:
+			level = slog.LevelError
+		case rec.status >= 400:
+			level = slog.LevelWarn
+		}
+		logger.LogAttrs(r.Context(), level, "request", attrs...)
+	})
+}
+
+// validRequestID reports whether a client supplied request ID is safe to reuse in logs and responses.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for _, c := range id {
+		if c < '!' || c > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// newRequestID returns a random 16 character hex ID.
+func newRequestID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%016x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
+
+// RecoveryMiddleware turns a panic in a handler into a 500 response carrying the request ID,
+// logs the stack trace and counts the panic in the server metrics.
+func RecoveryMiddleware(logger *slog.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			p := recover()
+			if p == nil {
+				return
+			}
+			// The client went away, let net/http abort the connection as intended
+			if p == http.ErrAbortHandler {
+				panic(p)
+			}
+
+			panicsTotal.Add(1)
+			panicsByPath.Add(r.URL.Path, 1)
+			logger.Error("handler panic",
+				slog.String("request_id", RequestID(r)),
+				slog.String("path", r.URL.Path),
+				slog.Any("panic", p),
+				slog.String("stack", string(debug.Stack())),
+			)
+
+			// Only send the error if the handler has not started the response yet
+			if rec, ok := w.(*statusRecorder); ok && rec.status != 0 {
+				return
+			}
+			writeError(w, r, "internal server error", http.StatusInternalServerError)
+			if info := getRequestInfo(r); info != nil {
+				info.Failure = fmt.Sprintf("panic: %v", p)
+			}
+		}()
+
+		next.ServeHTTP(w, r)
+	})
+}
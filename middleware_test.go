@@ -0,0 +1,189 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type MiddlewareTestSuite struct {
+	suite.Suite
+	logs   *bytes.Buffer
+	logger *slog.Logger
+}
+
+func (s *MiddlewareTestSuite) SetupTest() {
+	s.logs = &bytes.Buffer{}
+	s.logger = slog.New(slog.NewJSONHandler(s.logs, nil))
+}
+
+// Helper function to decode the single log line written for a request
+func (s *MiddlewareTestSuite) lastLogEntry() map[string]any {
+	var entry map[string]any
+	s.Require().NoError(json.Unmarshal(s.logs.Bytes(), &entry))
+	return entry
+}
+
+// Test for LoggingMiddleware
+func (s *MiddlewareTestSuite) TestLoggingMiddleware() {
+	tests := []struct {
+		name               string
+		endpoint           string
+		filePath           string
+		requestID          string
+		expectedStatusCode int
+		expectedLevel      string
+		expectedRows       float64
+		expectedError      string
+	}{
+		{
+			name:               "valid matrix logs dimensions",
+			endpoint:           "/sum",
+			filePath:           "testdata/valid_3_to_3.csv",
+			expectedStatusCode: 200,
+			expectedLevel:      "INFO",
+			expectedRows:       3,
+		},
+		{
+			name:               "caller request id is reused",
+			endpoint:           "/echo",
+			filePath:           "testdata/valid_2_to_2.csv",
+			requestID:          "abc-123",
+			expectedStatusCode: 200,
+			expectedLevel:      "INFO",
+			expectedRows:       2,
+		},
+		{
+			name:               "validation failure reason is logged",
+			endpoint:           "/invert",
+			filePath:           "testdata/more_rows_than_cols.csv",
+			requestID:          "bad-upload-1",
+			expectedStatusCode: 400,
+			expectedLevel:      "WARN",
+			expectedRows:       3,
+			expectedError:      "matrix is not square: 3 rows and 2 columns",
+		},
+		{
+			name:               "request id with whitespace is replaced",
+			endpoint:           "/flatten",
+			filePath:           "testdata/valid_2_to_2.csv",
+			requestID:          "bad id\nwith newline",
+			expectedStatusCode: 200,
+			expectedLevel:      "INFO",
+			expectedRows:       2,
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			s.logs.Reset()
+			req := createCSVRequest(s.T(), tc.endpoint, tc.filePath)
+			if tc.requestID != "" {
+				req.Header.Set(RequestIDHeader, tc.requestID)
+			}
+			w := httptest.NewRecorder()
+			NewRouter(Config{}, s.logger).ServeHTTP(w, req)
+
+			resp := w.Result()
+			body, _ := io.ReadAll(resp.Body)
+			s.Equal(tc.expectedStatusCode, resp.StatusCode)
+
+			// The request ID must be echoed in the response and match the log line
+			id := resp.Header.Get(RequestIDHeader)
+			s.NotEmpty(id)
+			if validRequestID(tc.requestID) {
+				s.Equal(tc.requestID, id)
+			} else {
+				s.NotEqual(tc.requestID, id)
+			}
+
+			entry := s.lastLogEntry()
+			s.Equal(id, entry["request_id"])
+			s.Equal("POST", entry["method"])
+			s.Equal(tc.endpoint, entry["path"])
+			s.Equal(float64(tc.expectedStatusCode), entry["status"])
+			s.Equal(tc.expectedLevel, entry["level"])
+			s.Equal(tc.expectedRows, entry["rows"])
+			s.Contains(entry, "duration")
+
+			if tc.expectedError != "" {
+				s.Equal(tc.expectedError, entry["error"])
+				s.Contains(string(body), tc.expectedError)
+				s.Contains(string(body), "request id: "+id)
+			} else {
+				s.NotContains(entry, "error")
+			}
+		})
+	}
+}
+
+// Test for RecoveryMiddleware
+func (s *MiddlewareTestSuite) TestRecoveryMiddleware() {
+	tests := []struct {
+		name               string
+		handler            http.HandlerFunc
+		expectedStatusCode int
+		expectPanicCounted bool
+	}{
+		{
+			name: "panic returns 500 with request id",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				var rows [][]string
+				_ = rows[1][0]
+			},
+			expectedStatusCode: 500,
+			expectPanicCounted: true,
+		},
+		{
+			name: "handler without panic is untouched",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte("ok"))
+			},
+			expectedStatusCode: 200,
+			expectPanicCounted: false,
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			s.logs.Reset()
+			panicsBefore := panicsTotal.Value()
+			handler := LoggingMiddleware(s.logger, RecoveryMiddleware(s.logger, tc.handler))
+
+			req := httptest.NewRequest("GET", "/panic", nil)
+			w := httptest.NewRecorder()
+			s.NotPanics(func() { handler.ServeHTTP(w, req) })
+
+			resp := w.Result()
+			body, _ := io.ReadAll(resp.Body)
+			s.Equal(tc.expectedStatusCode, resp.StatusCode)
+
+			if tc.expectPanicCounted {
+				id := resp.Header.Get(RequestIDHeader)
+				s.Equal("internal server error (request id: "+id+")\n", string(body))
+				s.Equal(panicsBefore+1, panicsTotal.Value())
+				s.Contains(s.logs.String(), "handler panic")
+				s.Contains(s.logs.String(), "goroutine")
+			} else {
+				s.Equal(panicsBefore, panicsTotal.Value())
+				s.NotContains(s.logs.String(), "handler panic")
+			}
+		})
+	}
+}
+
+// discardLogger returns a logger for tests that do not check the log output
+func discardLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+// Run all tests
+func TestMiddlewareTestSuite(t *testing.T) {
+	suite.Run(t, new(MiddlewareTestSuite))
+}
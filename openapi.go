@@ -0,0 +1,632 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"league_code_test/matrix"
+)
+
+// validationErrors are examples of the 400 errors returned by ParseCSVFile and matrix.ValidateSquare.
+var validationErrors = map[string]string{
+	"missingFile":  "failed to read file: http: no such file",
+	"invalidCSV":   "failed to parse csv: record on line 2; parse error on line 3, column 0: extraneous or missing \" in quoted-field",
+	"emptyFile":    "failed to parse csv: file is empty",
+	"headerRow":    "matrix has a header row or non-integer value at row 1, column 1",
+	"rowLength":    "matrix is not square: row 2 has 2 columns, expected 3",
+	"emptyValue":   "matrix has empty value at row 3, column 3",
+	"notAnInteger": "matrix value at row 3, column 2 is not an integer",
+	"notSquare":    "matrix is not square: 3 rows and 2 columns",
+}
+
+// outputExamples are the example responses of every output kind for the 3*3 matrix in matrix.csv.
+var outputExamples = map[string]string{
+	outputMatrix: "1,2,3\n4,5,6\n7,8,9\n",
+	outputList:   "1,2,3,4,5,6,7,8,9\n",
+	outputScalar: "45\n",
+	outputRREF:   "1,0,-1\n0,1,2\n0,0,0\n",
+	// The square of the matrix, with n=2
+	outputPower: "30,36,42\n66,81,96\n102,126,150\n",
+}
+
+// jsonSchemas are the names of the JSON output schemas of every output kind.
+var jsonSchemas = map[string]string{
+	outputMatrix:     "MatrixResult",
+	outputList:       "ListResult",
+	outputScalar:     "ScalarResult",
+	outputProperties: "PropertiesResult",
+	outputRREF:       "RREFResult",
+	outputFactors:    "FactorsResult",
+	outputEigen:      "EigenResult",
+	outputPower:      "PowerResult",
+	outputPolynomial: "PolynomialResult",
+}
+
+// inputParameters are the query parameters accepted by every matrix endpoint to read the matrix.
+var inputParameters = []map[string]any{
+	{
+		"name":        MatrixQueryParameter,
+		"in":          "query",
+		"description": "Small inline matrix used instead of the request body, with values separated by commas and rows by semicolons",
+		"schema":      map[string]any{"type": "string"},
+		"example":     "1,2;3,4",
+	},
+	{
+		"name":        "delimiter",
+		"in":          "query",
+		"description": "Value delimiter of the file: auto (detected from the file), comma, tab, semicolon, pipe, whitespace or a single character",
+		"schema":      map[string]any{"type": "string", "default": "auto"},
+	},
+	{
+		"name":        "lenient",
+		"in":          "query",
+		"description": "Normalize csv input before it is validated: strip a UTF-8 BOM, convert CRLF line endings, skip # comment and blank lines and trim whitespace around values. The applied normalizations are listed in the " + NormalizationsHeader + " response header",
+		"schema":      map[string]any{"type": "boolean", "default": false},
+	},
+	{
+		"name":        "header",
+		"in":          "query",
+		"description": "Read the first csv row as column labels, kept in matrix results",
+		"schema":      map[string]any{"type": "boolean", "default": false},
+	},
+	{
+		"name":        "labels",
+		"in":          "query",
+		"description": "Read the first csv column as row labels, kept in matrix results",
+		"schema":      map[string]any{"type": "boolean", "default": false},
+	},
+	{
+		"name":        "prefixes",
+		"in":          "query",
+		"description": "Read Go integer literals with a base prefix and underscores, such as 0xFF, 0b1010, 0o17 or 1_000",
+		"schema":      map[string]any{"type": "boolean", "default": false},
+	},
+}
+
+// baseParameter is the query parameter of the base of the integers in text output.
+var baseParameter = map[string]any{
+	"name":        "base",
+	"in":          "query",
+	"description": "Base of the integers in csv, table, latex, markdown and html output, written with their 0b, 0o or 0x prefix",
+	"schema":      map[string]any{"type": "integer", "enum": []int{2, 8, 10, 16}, "default": 10},
+}
+
+// modParameter is the query parameter of modular arithmetic.
+var modParameter = map[string]any{
+	"name":        "mod",
+	"in":          "query",
+	"description": "Compute modulo this integer, so that the results stay in [0, mod) however large the exact ones are",
+	"schema":      map[string]any{"type": "integer", "format": "int64", "minimum": 2},
+	"example":     1000000007,
+}
+
+// matrixOutputParameters are the query parameters of the matrix results, in every format of
+// matrixRenderers.
+var matrixOutputParameters = outputParameters(matrixFormats(), "Output format of matrices: csv, an aligned text table, json, Matrix Market with mtx (coordinate for sparse uploads, array otherwise), mtx-array or mtx-coordinate, a NumPy .npy array with npy, a LaTeX bmatrix, a GitHub Markdown table or an HTML table")
+
+// valueOutputParameters are the query parameters of the flatten, sum and product results.
+var valueOutputParameters = outputParameters(valueFormats, "Output format of values: csv, an aligned text table, json or a NumPy .npy array with npy")
+
+// outputParameters returns the query parameters of the text, JSON and binary results in the
+// formats.
+func outputParameters(formats []string, description string) []map[string]any {
+	return []map[string]any{
+		baseParameter,
+		{
+			"name":        "format",
+			"in":          "query",
+			"description": description,
+			"schema":      map[string]any{"type": "string", "enum": formats, "default": formatCSV},
+		},
+		{
+			"name":        "border",
+			"in":          "query",
+			"description": "Borders of table output: none to pad the columns with spaces, or box to draw them with box-drawing characters",
+			"schema":      map[string]any{"type": "string", "enum": []string{"none", "box"}, "default": "none"},
+		},
+		{
+			"name":        "indices",
+			"in":          "query",
+			"description": "Number the rows and columns of table output from 1, unless the matrix has labels",
+			"schema":      map[string]any{"type": "boolean", "default": false},
+		},
+		{
+			"name":        "max_size",
+			"in":          "query",
+			"description": "Rows and columns shown in table output, the middle ones are elided with ... beyond it",
+			"schema":      map[string]any{"type": "integer", "minimum": 2, "default": defaultTableMaxSize},
+		},
+	}
+}
+
+// heatmapParameters are the query parameters of heatmap images.
+var heatmapParameters = []map[string]any{
+	{
+		"name":        "format",
+		"in":          "query",
+		"description": "Image format of the heatmap, also selected with Accept: image/svg+xml",
+		"schema":      map[string]any{"type": "string", "enum": []string{imagePNG, imageSVG}, "default": imagePNG},
+	},
+	{
+		"name":        "scale",
+		"in":          "query",
+		"description": "Color scale of the heatmap, from the minimum to the maximum value, or centered on zero for diverging",
+		"schema":      map[string]any{"type": "string", "enum": colorScaleNames, "default": "viridis"},
+	},
+	{
+		"name":        "values",
+		"in":          "query",
+		"description": "Write the value of every cell over its color, in the cells wide enough for it",
+		"schema":      map[string]any{"type": "boolean", "default": false},
+	},
+	{
+		"name":        "legend",
+		"in":          "query",
+		"description": "Draw a color bar with the minimum and maximum values",
+		"schema":      map[string]any{"type": "boolean", "default": true},
+	},
+	{
+		"name":        "cell",
+		"in":          "query",
+		"description": "Width and height of the cells in pixels, by default so that the heatmap is about 512 pixels wide",
+		"schema":      map[string]any{"type": "integer", "minimum": 1, "maximum": 256},
+	},
+}
+
+// rrefParameters are the query parameters of the reduced row echelon form.
+var rrefParameters = []map[string]any{
+	{
+		"name":        "steps",
+		"in":          "query",
+		"description": "Return every row operation with the matrix after it, for matrices of up to 32x32 values",
+		"schema":      map[string]any{"type": "boolean", "default": false},
+	},
+	{
+		"name":        "format",
+		"in":          "query",
+		"description": "Output format: csv rows of fractions such as -1/2, or json",
+		"schema":      map[string]any{"type": "string", "enum": []string{formatCSV, formatJSON}, "default": formatCSV},
+	},
+}
+
+// factorsParameters are the query parameters of decompositions.
+var factorsParameters = []map[string]any{
+	{
+		"name":        "format",
+		"in":          "query",
+		"description": "Output format: a JSON object of the named factors, or a multipart/mixed response with a csv part per factor, also selected with Accept: multipart/mixed",
+		"schema":      map[string]any{"type": "string", "enum": []string{formatJSON, formatMultipart}, "default": formatJSON},
+	},
+}
+
+// modularParameters are the query parameters of the sum and product, which take a modulus.
+var modularParameters = append(slices.Clone(valueOutputParameters), modParameter)
+
+// powerParameters are the query parameters of matrix powers.
+var powerParameters = []map[string]any{
+	{
+		"name":        "n",
+		"in":          "query",
+		"required":    true,
+		"description": "Exponent of the power, 0 for the identity matrix. Without mod, the values of the result are limited to about 16 million bits in total",
+		"schema":      map[string]any{"type": "integer", "format": "int64", "minimum": 0},
+		"example":     1000,
+	},
+	modParameter,
+	{
+		"name":        "format",
+		"in":          "query",
+		"description": "Output format: csv or json, the only formats of values larger than int64",
+		"schema":      map[string]any{"type": "string", "enum": []string{formatCSV, formatJSON}, "default": formatCSV},
+	},
+	baseParameter,
+}
+
+// polynomialParameters are the query parameters of the characteristic polynomial.
+var polynomialParameters = []map[string]any{
+	{
+		"name":        "minimal",
+		"in":          "query",
+		"description": "Also return the minimal polynomial, the monic polynomial of least degree that is zero at the matrix, for matrices of up to " + strconv.Itoa(maxMinimalPolynomialSize) + "x" + strconv.Itoa(maxMinimalPolynomialSize) + " values",
+		"schema":      map[string]any{"type": "boolean", "default": false},
+	},
+}
+
+// eigenParameters are the query parameters of the eigenvalues.
+var eigenParameters = []map[string]any{
+	{
+		"name":        "tolerance",
+		"in":          "query",
+		"description": "Relative size below which a subdiagonal value of the Hessenberg form is taken as zero, the machine epsilon by default",
+		"schema":      map[string]any{"type": "number", "exclusiveMinimum": true, "minimum": 0, "exclusiveMaximum": true, "maximum": 1, "default": matrix.DefaultEigenTolerance},
+	},
+	{
+		"name":        "max_iterations",
+		"in":          "query",
+		"description": "Shifted QR steps taken before giving up with a 422 error, 30 times the number of rows by default",
+		"schema":      map[string]any{"type": "integer", "minimum": 1, "maximum": maxEigenIterations},
+	},
+}
+
+// BuildOpenAPISpec returns the OpenAPI 3 document describing every route in routes.
+func BuildOpenAPISpec(cfg Config) map[string]any {
+	paths := map[string]any{}
+	for _, rt := range routes {
+		paths[rt.path] = map[string]any{
+			"post": buildOperation(cfg, rt),
+		}
+	}
+
+	integerList := map[string]any{"type": "array", "items": map[string]any{"type": "integer", "format": "int64"}}
+	fraction := map[string]any{"type": "string", "description": "Exact fraction such as -1/2, or an integer", "example": "-1/2"}
+	fractionMatrix := map[string]any{"type": "array", "items": map[string]any{"type": "array", "items": fraction}}
+	polynomial := map[string]any{
+		"type":     "object",
+		"required": []string{"degree", "coefficients", "polynomial"},
+		"properties": map[string]any{
+			"degree":       map[string]any{"type": "integer", "example": 3},
+			"coefficients": map[string]any{"type": "array", "description": "From the leading coefficient down to the constant term", "items": map[string]any{"type": "integer"}, "example": []int{1, -15, -18, 0}},
+			"polynomial":   map[string]any{"type": "string", "example": "x^3 - 15x^2 - 18x"},
+		},
+	}
+	complexNumber := map[string]any{
+		"type":     "object",
+		"required": []string{"real", "imag"},
+		"properties": map[string]any{
+			"real": map[string]any{"type": "number", "example": 0.5},
+			"imag": map[string]any{"type": "number", "example": -1},
+		},
+	}
+	components := map[string]any{
+		"requestBodies": map[string]any{
+			"MatrixFile": map[string]any{
+				"description": "The matrix as a multipart file upload or as the raw request body, not needed with the " + MatrixQueryParameter + " query parameter",
+				"content": map[string]any{
+					"text/csv":                     rawBody("1,2,3\n4,5,6\n7,8,9\n"),
+					matrix.MatrixMarketContentType: rawBody("%%MatrixMarket matrix coordinate integer general\n3 3 2\n1 1 5\n3 2 -1\n"),
+					matrix.NPYContentType:          rawBody(""),
+					"application/gzip":             rawBody(""),
+					"multipart/form-data": map[string]any{
+						"schema": map[string]any{
+							"type":     "object",
+							"required": []string{"file"},
+							"properties": map[string]any{
+								"file": map[string]any{
+									"type":        "string",
+									"format":      "binary",
+									"description": "CSV file with a square matrix of integers and no header row, a Matrix Market file with the .mtx extension or the " + matrix.MatrixMarketContentType + " content type, or a NumPy .npy file with the .npy extension or the " + matrix.NPYContentType + " content type. Gzip compressed files such as matrix.csv.gz are decompressed, and so are request bodies sent with Content-Encoding: gzip",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		"responses": map[string]any{
+			"Error": map[string]any{
+				"description": "Error message followed by the request ID",
+				"content":     errorContent(nil),
+			},
+		},
+		"schemas": map[string]any{
+			"MatrixResult": map[string]any{
+				"type":     "object",
+				"required": []string{"values"},
+				"properties": map[string]any{
+					"values":        map[string]any{"type": "array", "items": integerList, "example": [][]int64{{1, 2}, {3, 4}}},
+					"row_labels":    map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+					"column_labels": map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+				},
+			},
+			"ListResult": map[string]any{
+				"type":       "object",
+				"required":   []string{"values"},
+				"properties": map[string]any{"values": integerList},
+			},
+			"ScalarResult": map[string]any{
+				"type":       "object",
+				"required":   []string{"value"},
+				"properties": map[string]any{"value": map[string]any{"type": "integer", "example": 45}},
+			},
+			"PowerResult": map[string]any{
+				"type":     "object",
+				"required": []string{"values"},
+				"properties": map[string]any{
+					"values":        map[string]any{"type": "array", "items": map[string]any{"type": "array", "items": map[string]any{"type": "integer"}}, "example": [][]int64{{30, 36, 42}, {66, 81, 96}, {102, 126, 150}}},
+					"row_labels":    map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+					"column_labels": map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+				},
+			},
+			"PolynomialResult": map[string]any{
+				"type":       "object",
+				"required":   []string{"characteristic"},
+				"properties": map[string]any{"characteristic": polynomial, "minimal": polynomial},
+			},
+			"PropertiesResult": map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"trace":            map[string]any{"type": "integer", "description": "Modulo mod when it is set", "example": 15},
+					"rank":             map[string]any{"type": "integer", "example": 2},
+					"determinant":      map[string]any{"type": "integer", "description": "Modulo mod when it is set", "example": 0},
+					"symmetric":        map[string]any{"type": "boolean"},
+					"skew_symmetric":   map[string]any{"type": "boolean"},
+					"diagonal":         map[string]any{"type": "boolean"},
+					"upper_triangular": map[string]any{"type": "boolean"},
+					"lower_triangular": map[string]any{"type": "boolean"},
+					"identity":         map[string]any{"type": "boolean"},
+					"orthogonal":       map[string]any{"type": "boolean", "description": "The matrix times its transpose is the identity"},
+					"permutation":      map[string]any{"type": "boolean"},
+				},
+			},
+			"RREFResult": map[string]any{
+				"type":     "object",
+				"required": []string{"values", "rank", "pivot_columns"},
+				"properties": map[string]any{
+					"values":        fractionMatrix,
+					"rank":          map[string]any{"type": "integer", "example": 2},
+					"pivot_columns": map[string]any{"type": "array", "items": map[string]any{"type": "integer"}, "example": []int{1, 2}},
+					"column_labels": map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+					"steps": map[string]any{
+						"type": "array",
+						"items": map[string]any{
+							"type":     "object",
+							"required": []string{"operation", "description", "row", "values"},
+							"properties": map[string]any{
+								"operation":   map[string]any{"type": "string", "enum": []string{matrix.RowSwap, matrix.RowScale, matrix.RowAdd}},
+								"description": map[string]any{"type": "string", "example": "R2 <- R2 - 4*R1"},
+								"row":         map[string]any{"type": "integer", "example": 2},
+								"source":      map[string]any{"type": "integer", "example": 1},
+								"factor":      map[string]any{"type": "string", "example": "-4"},
+								"values":      fractionMatrix,
+							},
+						},
+					},
+				},
+			},
+			"FactorsResult": map[string]any{
+				"type":                 "object",
+				"description":          "The factors by name: P, L and U for LU, Q and R for QR, and L for Cholesky",
+				"additionalProperties": map[string]any{"type": "array", "items": map[string]any{"type": "array", "items": map[string]any{"type": "number"}}},
+				"example":              map[string]any{"Q": [][]float64{{1, 0}, {0, 1}}, "R": [][]float64{{2, 1}, {0, 3}}},
+			},
+			"EigenResult": map[string]any{
+				"type":     "object",
+				"required": []string{"eigenvalues", "eigenvectors", "spectral_radius", "iterations"},
+				"properties": map[string]any{
+					"eigenvalues": map[string]any{
+						"type":        "array",
+						"description": "By decreasing real part then imaginary part",
+						"items":       complexNumber,
+					},
+					"eigenvectors": map[string]any{
+						"type":        "array",
+						"description": "The eigenvector of the eigenvalue at the same index, with unit length and its largest component real and positive",
+						"items":       map[string]any{"type": "array", "items": complexNumber},
+					},
+					"spectral_radius": map[string]any{"type": "number", "description": "Largest absolute value of the eigenvalues", "example": 1},
+					"iterations":      map[string]any{"type": "integer", "description": "Shifted QR steps taken", "example": 4},
+				},
+			},
+			"Problem": map[string]any{
+				"type":     "object",
+				"required": []string{"title", "status", "detail"},
+				"properties": map[string]any{
+					"title":      map[string]any{"type": "string", "example": "Bad Request"},
+					"status":     map[string]any{"type": "integer", "example": 400},
+					"detail":     map[string]any{"type": "string", "example": validationErrors["notSquare"]},
+					"request_id": map[string]any{"type": "string", "example": "3f9c2a7d41b0e865"},
+				},
+			},
+		},
+	}
+	if len(cfg.APIKeys) > 0 {
+		components["securitySchemes"] = map[string]any{
+			"ApiKey": map[string]any{"type": "apiKey", "in": "header", "name": APIKeyHeader},
+			"Bearer": map[string]any{"type": "http", "scheme": "bearer"},
+		}
+	}
+
+	return map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":       "League Matrix Service",
+			"description": "Operations on an uploaded CSV file with a square matrix of integers.",
+			"version":     "1.0.0",
+		},
+		"paths":      paths,
+		"components": components,
+	}
+}
+
+// buildOperation returns the OpenAPI operation of a route.
+func buildOperation(cfg Config, rt route) map[string]any {
+	errorResponse := map[string]any{"$ref": "#/components/responses/Error"}
+
+	examples := map[string]any{}
+	for key, message := range validationErrors {
+		examples[key] = map[string]any{"value": message}
+	}
+
+	responses := map[string]any{
+		"200": map[string]any{
+			"description": rt.summary,
+			"content":     outputContent(rt.output),
+		},
+		"400": map[string]any{
+			"description": "The file is missing, is not valid CSV or is not a square matrix of integers",
+			"content":     errorContent(examples),
+		},
+		"415": map[string]any{
+			"description": "The request body has a Content-Encoding other than gzip, such as zstd",
+			"content":     errorContent(nil),
+		},
+		"500": errorResponse,
+	}
+	if rt.output == outputScalar {
+		responses["406"] = map[string]any{
+			"description": "The result does not fit in an int64 value of the requested npy output",
+			"content":     errorContent(nil),
+		}
+	} else {
+		responses["413"] = map[string]any{
+			"description": "The matrix is too large for the operation or the output format, such as more than " + strconv.Itoa(maxDenseValues) + " values expanded from a sparse upload",
+			"content":     errorContent(nil),
+		}
+	}
+	if rt.output == outputFactors || rt.output == outputEigen {
+		responses["422"] = map[string]any{
+			"description": "The matrix has no such decomposition, such as a Cholesky decomposition of a matrix that is not symmetric positive-definite, or its eigenvalues did not converge",
+			"content":     errorContent(nil),
+		}
+	}
+	if len(cfg.RateLimits) > 0 || len(cfg.APIKeys) > 0 {
+		responses["429"] = rateLimitedResponse()
+	}
+
+	parameters := rt.parameters
+	if parameters == nil {
+		parameters = valueOutputParameters
+		if rt.output == outputMatrix {
+			parameters = matrixOutputParameters
+		}
+	}
+	operation := map[string]any{
+		"operationId": operationID(rt.path),
+		"summary":     rt.summary,
+		"tags":        []string{string(rt.cost)},
+		"parameters":  append(slices.Clone(inputParameters), parameters...),
+		"requestBody": map[string]any{"$ref": "#/components/requestBodies/MatrixFile"},
+		"responses":   responses,
+	}
+	if len(cfg.APIKeys) > 0 {
+		operation["security"] = []map[string][]string{{"ApiKey": {}}, {"Bearer": {}}}
+		responses["401"] = errorResponse
+		responses["403"] = errorResponse
+	}
+
+	return operation
+}
+
+// operationID returns the identifier of the operation at the path, such as decomposeLu for
+// /decompose/lu, so that generated clients can use it as a method name.
+func operationID(path string) string {
+	parts := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] != "" {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+// rateLimitedResponse returns the 429 response with its Retry-After header.
+func rateLimitedResponse() map[string]any {
+	return map[string]any{
+		"description": "Rate limit or quota exceeded",
+		"headers": map[string]any{
+			"Retry-After": map[string]any{
+				"description": "Seconds to wait before retrying",
+				"schema":      map[string]any{"type": "integer"},
+			},
+		},
+		"content": errorContent(nil),
+	}
+}
+
+// errorContent returns the content of an error response, as plain text or as a Problem
+// for clients that accept ProblemContentType.
+func errorContent(examples map[string]any) map[string]any {
+	text := map[string]any{"schema": map[string]any{"type": "string"}}
+	if examples != nil {
+		text["examples"] = examples
+	}
+	return map[string]any{
+		"text/plain":       text,
+		ProblemContentType: map[string]any{"schema": map[string]any{"$ref": "#/components/schemas/Problem"}},
+	}
+}
+
+// outputContent returns the content of a successful response of the output kind.
+func outputContent(output string) map[string]any {
+	jsonMediaType := map[string]any{"schema": map[string]any{"$ref": "#/components/schemas/" + jsonSchemas[output]}}
+	switch output {
+	case outputImage:
+		return map[string]any{
+			"image/png":     map[string]any{"schema": map[string]any{"type": "string", "format": "binary"}},
+			"image/svg+xml": map[string]any{"schema": map[string]any{"type": "string"}},
+		}
+	case outputFactors:
+		return map[string]any{
+			"application/json": jsonMediaType,
+			"multipart/mixed": map[string]any{
+				"schema": map[string]any{"type": "string"},
+				"example": "--boundary\r\nContent-Disposition: attachment; name=\"Q\"; filename=\"Q.csv\"\r\nContent-Type: text/csv\r\n\r\n" +
+					"1,0\n0,1\n\r\n--boundary\r\nContent-Disposition: attachment; name=\"R\"; filename=\"R.csv\"\r\nContent-Type: text/csv\r\n\r\n" +
+					"2,1\n0,3\n\r\n--boundary--\r\n",
+			},
+		}
+	case outputMatrix, outputList, outputScalar:
+	default:
+		// Linear algebra results are returned as JSON, and as text when they have an example
+		content := map[string]any{"application/json": jsonMediaType}
+		if example, ok := outputExamples[output]; ok {
+			content["text/plain"] = textMediaType(example)
+		}
+		return content
+	}
+
+	content := textContent(outputExamples[output])
+	content[matrix.NPYContentType] = map[string]any{
+		"schema": map[string]any{"type": "string", "format": "binary"},
+	}
+	content["application/json"] = jsonMediaType
+	if output == outputMatrix {
+		content[matrix.MatrixMarketContentType] = map[string]any{
+			"schema":  map[string]any{"type": "string"},
+			"example": "%%MatrixMarket matrix coordinate integer general\n3 3 2\n1 1 5\n3 2 -1\n",
+		}
+		content["application/x-latex"] = textMediaType("\\begin{bmatrix}\n1 & 2 & 3 \\\\\n4 & 5 & 6 \\\\\n7 & 8 & 9\n\\end{bmatrix}\n")
+		content["text/markdown"] = textMediaType("| 1 | 2 | 3 |\n| ---: | ---: | ---: |\n| 1 | 2 | 3 |\n| 4 | 5 | 6 |\n| 7 | 8 | 9 |\n")
+		content["text/html"] = textMediaType("<table>\n<tbody>\n<tr><td>1</td><td>2</td><td>3</td></tr>\n<tr><td>4</td><td>5</td><td>6</td></tr>\n<tr><td>7</td><td>8</td><td>9</td></tr>\n</tbody>\n</table>\n")
+	}
+	return content
+}
+
+// rawBody returns the media type of a request body sent as is, with an optional example.
+func rawBody(example string) map[string]any {
+	mediaType := map[string]any{"schema": map[string]any{"type": "string", "format": "binary"}}
+	if example != "" {
+		mediaType["example"] = example
+	}
+	return mediaType
+}
+
+// textContent returns a text/plain content object with an optional example.
+func textContent(example string) map[string]any {
+	return map[string]any{"text/plain": textMediaType(example)}
+}
+
+// textMediaType returns the media type of a text response with an optional example.
+func textMediaType(example string) map[string]any {
+	mediaType := map[string]any{"schema": map[string]any{"type": "string"}}
+	if example != "" {
+		mediaType["example"] = example
+	}
+	return mediaType
+}
+
+// OpenAPIHandler serves the OpenAPI document of the server as JSON.
+func OpenAPIHandler(cfg Config) http.Handler {
+	spec, err := json.MarshalIndent(BuildOpenAPISpec(cfg), "", "  ")
+	if err != nil {
+		panic(err)
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(spec)
+	})
+}
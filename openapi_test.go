@@ -0,0 +1,135 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type OpenAPITestSuite struct {
+	suite.Suite
+}
+
+// Helper function to fetch and decode the spec served by the router
+func (s *OpenAPITestSuite) fetchSpec(cfg Config) map[string]any {
+	req := httptest.NewRequest("GET", "/openapi.json", nil)
+	w := httptest.NewRecorder()
+	NewRouter(cfg, discardLogger()).ServeHTTP(w, req)
+
+	s.Require().Equal(200, w.Code)
+	s.Equal("application/json", w.Header().Get("Content-Type"))
+
+	var spec map[string]any
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &spec))
+	return spec
+}
+
+// servedPaths are the matrix endpoints served by the router, listed here rather than taken from
+// routes so that the spec is checked against an independent list.
+var servedPaths = []string{
+	"/echo", "/invert", "/flatten", "/sum", "/multiply", "/render", "/properties", "/rref", "/power",
+	"/charpoly", "/decompose/lu", "/decompose/qr", "/decompose/cholesky", "/eigen",
+}
+
+// Test that every served endpoint is described in the spec, and nothing else
+func (s *OpenAPITestSuite) TestEveryRouteIsDocumented() {
+	spec := s.fetchSpec(Config{})
+	s.Equal("3.0.3", spec["openapi"])
+
+	router := NewRouter(Config{}, discardLogger())
+	paths := spec["paths"].(map[string]any)
+	s.Len(paths, len(servedPaths))
+	for _, path := range servedPaths {
+		s.Run(path, func() {
+			req := httptest.NewRequest("POST", path+"?matrix=2,1;1,2&n=2", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			s.Equal(200, w.Code, "%s is not served: %s", path, w.Body.String())
+
+			s.Require().Contains(paths, path, "route %s is missing from the OpenAPI spec", path)
+			operation := paths[path].(map[string]any)["post"].(map[string]any)
+
+			s.Regexp(`^[a-z][A-Za-z]*$`, operation["operationId"])
+			s.NotEmpty(operation["summary"])
+			s.Equal("#/components/requestBodies/MatrixFile", operation["requestBody"].(map[string]any)["$ref"])
+
+			responses := operation["responses"].(map[string]any)
+			s.Contains(responses, "200")
+			s.Contains(responses, "400")
+			s.Contains(responses, "500")
+			s.NotContains(responses, "401")
+			if path == "/sum" || path == "/multiply" {
+				s.Contains(responses, "406")
+			} else {
+				s.Contains(responses, "413")
+			}
+		})
+	}
+}
+
+// Test that every documented output format is accepted by its endpoint
+func (s *OpenAPITestSuite) TestDocumentedFormatsAreAccepted() {
+	router := NewRouter(Config{}, discardLogger())
+	paths := s.fetchSpec(Config{})["paths"].(map[string]any)
+	for path, item := range paths {
+		operation := item.(map[string]any)["post"].(map[string]any)
+		for _, parameter := range operation["parameters"].([]any) {
+			parameter := parameter.(map[string]any)
+			if parameter["name"] != "format" {
+				continue
+			}
+			for _, format := range parameter["schema"].(map[string]any)["enum"].([]any) {
+				s.Run(path+" "+format.(string), func() {
+					req := httptest.NewRequest("POST", path+"?matrix=2,1;1,2&n=2&format="+format.(string), nil)
+					w := httptest.NewRecorder()
+					router.ServeHTTP(w, req)
+					s.NotContains(w.Body.String(), "is not supported for this result")
+					s.Less(w.Code, 400, w.Body.String())
+				})
+			}
+		}
+	}
+}
+
+// Test that the routes only serve the documented post method
+func (s *OpenAPITestSuite) TestOnlyPostIsServed() {
+	router := NewRouter(Config{}, discardLogger())
+	for _, method := range []string{"GET", "PUT", "DELETE"} {
+		req := httptest.NewRequest(method, "/sum?matrix=1,2;3,4", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		s.Equal(405, w.Code, method)
+		s.Equal("POST", w.Header().Get("Allow"), method)
+	}
+}
+
+// Test that operation IDs are identifiers
+func (s *OpenAPITestSuite) TestOperationID() {
+	s.Equal("sum", operationID("/sum"))
+	s.Equal("decomposeLu", operationID("/decompose/lu"))
+	s.Equal("decomposeCholesky", operationID("/decompose/cholesky"))
+}
+
+// Test that authentication and rate limits are documented when configured
+func (s *OpenAPITestSuite) TestSecurityIsDocumented() {
+	keys, err := ParseAPIKeys("secret")
+	s.Require().NoError(err)
+	spec := s.fetchSpec(Config{APIKeys: keys})
+
+	components := spec["components"].(map[string]any)
+	s.Contains(components, "securitySchemes")
+
+	operation := spec["paths"].(map[string]any)["/sum"].(map[string]any)["post"].(map[string]any)
+	s.NotEmpty(operation["security"])
+	responses := operation["responses"].(map[string]any)
+	for _, code := range []string{"401", "403", "429"} {
+		s.Contains(responses, code)
+	}
+}
+
+// Run all tests
+func TestOpenAPITestSuite(t *testing.T) {
+	suite.Run(t, new(OpenAPITestSuite))
+}
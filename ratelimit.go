@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// costClass groups operations that share a rate limit budget.
+type costClass string
+
+const (
+	// costCheap is for operations linear in the matrix size, such as echo and sum
+	costCheap costClass = "cheap"
+	// costExpensive is for operations with big integer or super-linear work, such as multiply
+	costExpensive costClass = "expensive"
+)
+
+// RateLimit is a token bucket budget: Rate requests per second on average, up to Burst at once.
+// A zero Rate disables the limit.
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+// bucket is the token bucket of one client for one cost class.
+type bucket struct {
+	tokens float64
+	last   time.Time
+	// fullAt is when the bucket is refilled to its burst size
+	fullAt time.Time
+}
+
+// RateLimiter limits requests per client, keyed by the identifier of the API key or by IP
+// address, with a separate budget for every cost class.
+type RateLimiter struct {
+	mu        sync.Mutex
+	limits    map[costClass]RateLimit
+	buckets   map[string]*bucket
+	now       func() time.Time
+	lastSweep time.Time
+}
+
+// NewRateLimiter returns a RateLimiter with the given budget for every cost class.
+func NewRateLimiter(limits map[costClass]RateLimit) *RateLimiter {
+	return &RateLimiter{
+		limits:  limits,
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+// Limit rejects requests with a 429 and a Retry-After header once the client has used up
+// its budget for the cost class of the handler.
+func (rl *RateLimiter) Limit(class costClass, next http.Handler) http.Handler {
+	limit := rl.limits[class]
+	if limit.Rate <= 0 {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		client := rateLimitClient(r)
+		if wait := rl.take(string(class)+"|"+client, limit); wait > 0 {
+			w.Header().Set("Retry-After", retryAfterSeconds(wait))
+			writeError(w, r, fmt.Sprintf("rate limit exceeded for %s operations: %g requests per second", class, limit.Rate), http.StatusTooManyRequests)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// take removes one token from the bucket and returns 0, or returns how long to wait for the next token.
+func (rl *RateLimiter) take(key string, limit RateLimit) time.Duration {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	now := rl.now()
+	rl.sweep(now)
+
+	burst := float64(max(limit.Burst, 1))
+	b, ok := rl.buckets[key]
+	if !ok {
+		b = &bucket{tokens: burst, last: now}
+		rl.buckets[key] = b
+	}
+
+	// Refill the tokens earned since the last request
+	b.tokens = min(burst, b.tokens+now.Sub(b.last).Seconds()*limit.Rate)
+	b.last = now
+
+	if b.tokens < 1 {
+		return secondsToDuration((1 - b.tokens) / limit.Rate)
+	}
+	b.tokens--
+	b.fullAt = now.Add(secondsToDuration((burst - b.tokens) / limit.Rate))
+	return 0
+}
+
+// sweep drops the buckets that are full again, since a new bucket starts full anyway.
+// It runs at most once a minute. The caller must hold rl.mu.
+func (rl *RateLimiter) sweep(now time.Time) {
+	if now.Sub(rl.lastSweep) < time.Minute {
+		return
+	}
+	rl.lastSweep = now
+	for key, b := range rl.buckets {
+		if !now.Before(b.fullAt) {
+			delete(rl.buckets, key)
+		}
+	}
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// rateLimitClient identifies the client by API key when authenticated, or by IP address.
+func rateLimitClient(r *http.Request) string {
+	if info := getRequestInfo(r); info != nil && info.KeyID != "" {
+		return "key:" + info.KeyID
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
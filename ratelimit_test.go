@@ -0,0 +1,148 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type RateLimitTestSuite struct {
+	suite.Suite
+}
+
+// Test for the token bucket of RateLimiter
+func (s *RateLimitTestSuite) TestTake() {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	rl := NewRateLimiter(nil)
+	rl.now = func() time.Time { return now }
+	limit := RateLimit{Rate: 2, Burst: 3}
+
+	// The burst is available at once, then one token every 500ms
+	s.Zero(rl.take("client", limit))
+	s.Zero(rl.take("client", limit))
+	s.Zero(rl.take("client", limit))
+	s.Equal(500*time.Millisecond, rl.take("client", limit))
+
+	now = now.Add(250 * time.Millisecond)
+	s.Equal(250*time.Millisecond, rl.take("client", limit))
+	now = now.Add(250 * time.Millisecond)
+	s.Zero(rl.take("client", limit))
+
+	// Other clients have their own bucket
+	s.Zero(rl.take("other", limit))
+
+	// Idle clients are swept once their bucket is full again
+	now = now.Add(2 * time.Minute)
+	rl.take("other", limit)
+	s.NotContains(rl.buckets, "client")
+	s.Contains(rl.buckets, "other")
+}
+
+// Test for ParseRateLimit
+func (s *RateLimitTestSuite) TestParseRateLimit() {
+	tests := []struct {
+		value       string
+		expected    RateLimit
+		errorSubstr string
+	}{
+		{value: "10/20", expected: RateLimit{Rate: 10, Burst: 20}},
+		{value: "0.5", expected: RateLimit{Rate: 0.5, Burst: 1}},
+		{value: "3", expected: RateLimit{Rate: 3, Burst: 3}},
+		{value: "fast", errorSubstr: "rate must be a positive number"},
+		{value: "-1/5", errorSubstr: "rate must be a positive number"},
+		{value: "NaN/5", errorSubstr: "rate must be a positive number"},
+		{value: "Inf", errorSubstr: "rate must be a positive number"},
+		{value: "-Inf/1", errorSubstr: "rate must be a positive number"},
+		{value: "1/0", errorSubstr: "burst must be a positive integer"},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.value, func() {
+			limit, err := ParseRateLimit(tc.value)
+			if tc.errorSubstr != "" {
+				s.ErrorContains(err, tc.errorSubstr)
+			} else {
+				s.NoError(err)
+				s.Equal(tc.expected, limit)
+			}
+		})
+	}
+}
+
+// Test that the README lists every operation under its rate limit budget
+func (s *RateLimitTestSuite) TestREADMEListsEveryOperation() {
+	readme, err := os.ReadFile("README.md")
+	s.Require().NoError(err)
+
+	listed := map[costClass][]string{}
+	for class, name := range map[costClass]string{costCheap: "RATE_LIMIT_CHEAP", costExpensive: "RATE_LIMIT_EXPENSIVE"} {
+		match := regexp.MustCompile("`" + name + "` \\(([^)]*)\\)").FindSubmatch(readme)
+		s.Require().NotNil(match, "%s is not documented", name)
+		listed[class] = strings.Split(string(match[1]), ", ")
+	}
+	for _, rt := range routes {
+		s.Contains(listed[rt.cost], rt.path[1:], "%s is not listed under its %s budget", rt.path, rt.cost)
+	}
+}
+
+// Test that cheap and expensive operations have separate budgets through the router
+func (s *RateLimitTestSuite) TestRouterBudgets() {
+	cfg := Config{RateLimits: map[costClass]RateLimit{
+		costCheap:     {Rate: 100, Burst: 100},
+		costExpensive: {Rate: 0.1, Burst: 1},
+	}}
+	router := NewRouter(cfg, discardLogger())
+
+	serve := func(endpoint string, remoteAddr string) *http.Response {
+		req := createCSVRequest(s.T(), endpoint, "testdata/valid_2_to_2.csv")
+		req.RemoteAddr = remoteAddr
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Result()
+	}
+
+	s.Equal(200, serve("/multiply", "10.0.0.1:1234").StatusCode)
+
+	resp := serve("/multiply", "10.0.0.1:5678")
+	s.Equal(429, resp.StatusCode)
+	s.Equal("10", resp.Header.Get("Retry-After"))
+
+	// The expensive budget does not starve cheap operations or other clients
+	s.Equal(200, serve("/echo", "10.0.0.1:1234").StatusCode)
+	s.Equal(200, serve("/sum", "10.0.0.1:1234").StatusCode)
+	s.Equal(200, serve("/multiply", "10.0.0.2:1234").StatusCode)
+}
+
+// Test that API keys sharing a name have separate budgets
+func (s *RateLimitTestSuite) TestKeyBudgets() {
+	cfg := Config{
+		APIKeys: []APIKey{{Key: "key-a", Name: "team"}, {Key: "key-b", Name: "team"}},
+		RateLimits: map[costClass]RateLimit{
+			costExpensive: {Rate: 0.1, Burst: 1},
+		},
+	}
+	router := NewRouter(cfg, discardLogger())
+
+	serve := func(apiKey string) *http.Response {
+		req := createCSVRequest(s.T(), "/multiply", "testdata/valid_2_to_2.csv")
+		req.Header.Set(APIKeyHeader, apiKey)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Result()
+	}
+
+	s.Equal(200, serve("key-a").StatusCode)
+	s.Equal(429, serve("key-a").StatusCode)
+	s.Equal(200, serve("key-b").StatusCode)
+}
+
+// Run all tests
+func TestRateLimitTestSuite(t *testing.T) {
+	suite.Run(t, new(RateLimitTestSuite))
+}
@@ -0,0 +1,310 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"league_code_test/matrix"
+)
+
+// matrixRenderer writes matrix results in an output format, selected with the format query
+// parameter or with the Accept header.
+type matrixRenderer struct {
+	format string
+	// mediaType selects the format with the Accept header, unless it is empty
+	mediaType string
+	// bases is set for the text formats that can write values in another base than 10
+	bases  bool
+	render func(w http.ResponseWriter, r *http.Request, in input, base int)
+}
+
+// matrixRenderers are the output formats of every matrix-returning endpoint, in the order
+// that they are chosen when the Accept header lists several of them. A format added here is
+// served by writeMatrix and documented in the OpenAPI specification.
+var matrixRenderers = []matrixRenderer{
+	{format: formatCSV, bases: true, render: renderCSV},
+	{format: formatTable, bases: true, render: dense(writeMatrixTable)},
+	{format: formatMTX, mediaType: matrix.MatrixMarketContentType, render: renderMTX},
+	{format: formatMTXArray, render: dense(renderMTXArray)},
+	{format: formatMTXCoordinate, render: renderMTXCoordinate},
+	{format: formatNPY, mediaType: matrix.NPYContentType, render: dense(renderNPY)},
+	{format: formatJSON, mediaType: "application/json", render: dense(renderJSON)},
+	{format: formatLaTeX, mediaType: "application/x-latex", bases: true, render: dense(renderLaTeX)},
+	{format: formatMarkdown, mediaType: "text/markdown", bases: true, render: dense(renderMarkdown)},
+	{format: formatHTML, mediaType: "text/html", bases: true, render: dense(renderHTML)},
+}
+
+// valueFormats are the output formats of the flatten, sum and multiply results.
+var valueFormats = []string{formatCSV, formatTable, formatNPY, formatJSON}
+
+// matrixFormats returns the output formats of matrix results.
+func matrixFormats() []string {
+	formats := make([]string, len(matrixRenderers))
+	for i, rd := range matrixRenderers {
+		formats[i] = rd.format
+	}
+	return formats
+}
+
+// findRenderer returns the renderer of the output format.
+func findRenderer(format string) (matrixRenderer, bool) {
+	i := slices.IndexFunc(matrixRenderers, func(rd matrixRenderer) bool { return rd.format == format })
+	if i < 0 {
+		return matrixRenderer{}, false
+	}
+	return matrixRenderers[i], true
+}
+
+// outputFormat returns the output format among the formats of the result, requested with the
+// format query parameter or with the Accept header, csv by default.
+func outputFormat(r *http.Request, formats []string) (string, error) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		// The media type with the highest quality wins, the first renderer on ties
+		accept := r.Header.Get("Accept")
+		best, bestQuality := formatCSV, 0.0
+		for _, rd := range matrixRenderers {
+			if rd.mediaType == "" || !slices.Contains(formats, rd.format) {
+				continue
+			}
+			if q := acceptQuality(accept, rd.mediaType); q > bestQuality {
+				best, bestQuality = rd.format, q
+			}
+		}
+		return best, nil
+	}
+
+	if slices.Contains(formats, format) {
+		return format, nil
+	}
+	if _, ok := findRenderer(format); ok {
+		return "", fmt.Errorf("format %q is not supported for this result: use %s", format, joinOr(formats))
+	}
+	return "", fmt.Errorf("invalid format %q: use %s", format, joinOr(formats))
+}
+
+// acceptQuality returns the quality of the media type in the Accept header, from 0 when it is
+// not listed or listed with q=0 to 1, such as 0.5 for "text/html;q=0.5, */*;q=0.1". Only the
+// media ranges naming the type exactly count, as */* is served with the default format.
+func acceptQuality(accept string, mediaType string) float64 {
+	quality := 0.0
+	for _, part := range strings.Split(accept, ",") {
+		mediaRange, params, _ := strings.Cut(part, ";")
+		if !strings.EqualFold(strings.TrimSpace(mediaRange), mediaType) {
+			continue
+		}
+		q := 1.0
+		for _, param := range strings.Split(params, ";") {
+			name, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if strings.TrimSpace(name) == "q" {
+				if parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil && parsed >= 0 && parsed <= 1 {
+					q = parsed
+				}
+			}
+		}
+		quality = max(quality, q)
+	}
+	return quality
+}
+
+// joinOr returns the words separated by commas, with "or" before the last one.
+func joinOr(words []string) string {
+	if len(words) < 2 {
+		return strings.Join(words, "")
+	}
+	return strings.Join(words[:len(words)-1], ", ") + " or " + words[len(words)-1]
+}
+
+// writeMatrix writes the matrix with the renderer of the requested output format.
+func writeMatrix(w http.ResponseWriter, r *http.Request, in input) {
+	format, err := outputFormat(r, matrixFormats())
+	if err != nil {
+		writeError(w, r, err.Error(), http.StatusBadRequest)
+		return
+	}
+	base, err := outputBase(r, format)
+	if err != nil {
+		writeError(w, r, err.Error(), http.StatusBadRequest)
+		return
+	}
+	rd, _ := findRenderer(format)
+	rd.render(w, r, in, base)
+}
+
+// dense adapts a renderer of in-memory matrices, expanding sparse uploads up to maxDenseValues.
+func dense(render func(w http.ResponseWriter, r *http.Request, m *matrix.Matrix, base int)) func(http.ResponseWriter, *http.Request, input, int) {
+	return func(w http.ResponseWriter, r *http.Request, in input, base int) {
+		m, err := in.Matrix()
+		if err != nil {
+			writeError(w, r, err.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+		render(w, r, m, base)
+	}
+}
+
+// renderCSV writes the matrix as csv rows, streamed from sparse uploads, with the labels of
+// labeled input as the header row and first column.
+func renderCSV(w http.ResponseWriter, r *http.Request, in input, base int) {
+	if in.dense != nil && (in.dense.RowLabels != nil || in.dense.ColLabels != nil) {
+		writeLabeledCSV(w, in.dense, base)
+		return
+	}
+	if err := in.checkDenseSize(); err != nil {
+		writeError(w, r, err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	}
+	// Each row is a list of integers, such as [1, 2, 3]
+	bw := bufio.NewWriter(w)
+	in.rows(func(row []int64) {
+		bw.WriteString(formatRow(row, base))
+		bw.WriteString("\n")
+	})
+	bw.Flush()
+}
+
+// renderMTX writes sparse uploads in the Matrix Market coordinate format and dense ones in
+// the array format.
+func renderMTX(w http.ResponseWriter, r *http.Request, in input, base int) {
+	if in.sparse != nil {
+		renderMTXCoordinate(w, r, in, base)
+		return
+	}
+	renderMTXArray(w, r, in.dense, base)
+}
+
+func renderMTXCoordinate(w http.ResponseWriter, r *http.Request, in input, base int) {
+	sparse := in.sparse
+	if sparse == nil {
+		sparse = in.dense.Sparse()
+	}
+	w.Header().Set("Content-Type", matrix.MatrixMarketContentType)
+	matrix.WriteMatrixMarketCoordinate(w, sparse)
+}
+
+func renderMTXArray(w http.ResponseWriter, r *http.Request, m *matrix.Matrix, base int) {
+	w.Header().Set("Content-Type", matrix.MatrixMarketContentType)
+	matrix.WriteMatrixMarketArray(w, m)
+}
+
+func renderNPY(w http.ResponseWriter, r *http.Request, m *matrix.Matrix, base int) {
+	w.Header().Set("Content-Type", matrix.NPYContentType)
+	matrix.WriteNPY(w, []int{m.Size(), m.Size()}, m.Flatten())
+}
+
+func renderJSON(w http.ResponseWriter, r *http.Request, m *matrix.Matrix, base int) {
+	writeJSON(w, r, matrixJSON{Values: m.Values, RowLabels: m.RowLabels, ColLabels: m.ColLabels})
+}
+
+// renderLaTeX writes the matrix as a LaTeX bmatrix environment from the amsmath package.
+// Labels have no place in a bmatrix and are left out.
+func renderLaTeX(w http.ResponseWriter, r *http.Request, m *matrix.Matrix, base int) {
+	w.Header().Set("Content-Type", "application/x-latex; charset=utf-8")
+	writeLaTeX(w, m, base)
+}
+
+func writeLaTeX(w io.Writer, m *matrix.Matrix, base int) {
+	bw := bufio.NewWriter(w)
+	bw.WriteString("\\begin{bmatrix}\n")
+	for i, row := range m.Values {
+		cells := make([]string, len(row))
+		for j, num := range row {
+			cells[j] = formatInt(num, base)
+		}
+		bw.WriteString(strings.Join(cells, " & "))
+		if i < len(m.Values)-1 {
+			bw.WriteString(" \\\\")
+		}
+		bw.WriteString("\n")
+	}
+	bw.WriteString("\\end{bmatrix}\n")
+	bw.Flush()
+}
+
+// renderMarkdown writes the matrix as a GitHub Flavored Markdown table with right-aligned
+// values. The column labels, or the column numbers from 1, are the header row, which GitHub
+// requires, and the row labels are the first column.
+func renderMarkdown(w http.ResponseWriter, r *http.Request, m *matrix.Matrix, base int) {
+	w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+	writeMarkdown(w, m, base)
+}
+
+func writeMarkdown(w io.Writer, m *matrix.Matrix, base int) {
+	colLabels := m.ColLabels
+	if colLabels == nil {
+		colLabels = indexLabels(m.Size())
+	}
+
+	var header, delimiter []string
+	if m.RowLabels != nil {
+		header, delimiter = append(header, ""), append(delimiter, "---")
+	}
+	for _, label := range colLabels {
+		header, delimiter = append(header, markdownEscape(label)), append(delimiter, "---:")
+	}
+
+	bw := bufio.NewWriter(w)
+	writeRow := func(cells []string) {
+		bw.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+	}
+	writeRow(header)
+	writeRow(delimiter)
+	for i, row := range m.Values {
+		var cells []string
+		if m.RowLabels != nil {
+			cells = append(cells, markdownEscape(m.RowLabels[i]))
+		}
+		for _, num := range row {
+			cells = append(cells, formatInt(num, base))
+		}
+		writeRow(cells)
+	}
+	bw.Flush()
+}
+
+// markdownEscape escapes the characters of a label that would break a Markdown table cell.
+func markdownEscape(label string) string {
+	return strings.NewReplacer(`\`, `\\`, "|", `\|`, "\n", " ").Replace(label)
+}
+
+// renderHTML writes the matrix as an HTML table, with the column labels in a thead and the
+// row labels as row headers.
+func renderHTML(w http.ResponseWriter, r *http.Request, m *matrix.Matrix, base int) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	writeHTML(w, m, base)
+}
+
+func writeHTML(w io.Writer, m *matrix.Matrix, base int) {
+	bw := bufio.NewWriter(w)
+	bw.WriteString("<table>\n")
+	if m.ColLabels != nil {
+		bw.WriteString("<thead>\n<tr>")
+		if m.RowLabels != nil {
+			bw.WriteString("<th></th>")
+		}
+		for _, label := range m.ColLabels {
+			bw.WriteString(`<th scope="col">` + html.EscapeString(label) + "</th>")
+		}
+		bw.WriteString("</tr>\n</thead>\n")
+	}
+	bw.WriteString("<tbody>\n")
+	for i, row := range m.Values {
+		bw.WriteString("<tr>")
+		if m.RowLabels != nil {
+			bw.WriteString(`<th scope="row">` + html.EscapeString(m.RowLabels[i]) + "</th>")
+		}
+		for _, num := range row {
+			bw.WriteString("<td>" + formatInt(num, base) + "</td>")
+		}
+		bw.WriteString("</tr>\n")
+	}
+	bw.WriteString("</tbody>\n</table>\n")
+	bw.Flush()
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"league_code_test/matrix"
+)
+
+type RenderersTestSuite struct {
+	suite.Suite
+}
+
+// Test for the LaTeX, Markdown and HTML writers
+func (s *RenderersTestSuite) TestWriters() {
+	plain := &matrix.Matrix{Values: [][]int64{{1, -2}, {30, 4}}}
+	labeled := &matrix.Matrix{
+		Values:    [][]int64{{1, 2}, {3, 4}},
+		RowLabels: []string{"x|y", "<z>"},
+		ColLabels: []string{"a & b", "c"},
+	}
+
+	tests := []struct {
+		name     string
+		write    func(w io.Writer, m *matrix.Matrix, base int)
+		m        *matrix.Matrix
+		base     int
+		expected string
+	}{
+		{
+			name:     "latex",
+			write:    writeLaTeX,
+			m:        plain,
+			base:     10,
+			expected: "\\begin{bmatrix}\n1 & -2 \\\\\n30 & 4\n\\end{bmatrix}\n",
+		},
+		{
+			name:     "hexadecimal latex",
+			write:    writeLaTeX,
+			m:        plain,
+			base:     16,
+			expected: "\\begin{bmatrix}\n0x1 & -0x2 \\\\\n0x1e & 0x4\n\\end{bmatrix}\n",
+		},
+		{
+			name:     "markdown numbers the columns",
+			write:    writeMarkdown,
+			m:        plain,
+			base:     10,
+			expected: "| 1 | 2 |\n| ---: | ---: |\n| 1 | -2 |\n| 30 | 4 |\n",
+		},
+		{
+			name:     "markdown with escaped labels",
+			write:    writeMarkdown,
+			m:        labeled,
+			base:     10,
+			expected: "|  | a & b | c |\n| --- | ---: | ---: |\n| x\\|y | 1 | 2 |\n| <z> | 3 | 4 |\n",
+		},
+		{
+			name:     "html",
+			write:    writeHTML,
+			m:        plain,
+			base:     10,
+			expected: "<table>\n<tbody>\n<tr><td>1</td><td>-2</td></tr>\n<tr><td>30</td><td>4</td></tr>\n</tbody>\n</table>\n",
+		},
+		{
+			name:  "html with escaped labels",
+			write: writeHTML,
+			m:     labeled,
+			base:  10,
+			expected: "<table>\n<thead>\n<tr><th></th><th scope=\"col\">a &amp; b</th><th scope=\"col\">c</th></tr>\n</thead>\n" +
+				"<tbody>\n<tr><th scope=\"row\">x|y</th><td>1</td><td>2</td></tr>\n" +
+				"<tr><th scope=\"row\">&lt;z&gt;</th><td>3</td><td>4</td></tr>\n</tbody>\n</table>\n",
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			var buf bytes.Buffer
+			tc.write(&buf, tc.m, tc.base)
+			s.Equal(tc.expected, buf.String())
+		})
+	}
+}
+
+// Test for the renderers through the router, selected with the format parameter or the Accept header
+func (s *RenderersTestSuite) TestRenderers() {
+	tests := []struct {
+		name                   string
+		endpoint               string
+		filePath               string
+		accept                 string
+		expectedStatusCode     int
+		expectedContentType    string
+		expectedResponseSubstr string
+	}{
+		{
+			name:                   "latex format",
+			endpoint:               "/echo?format=latex",
+			filePath:               "testdata/valid_2_to_2.csv",
+			expectedStatusCode:     200,
+			expectedContentType:    "application/x-latex; charset=utf-8",
+			expectedResponseSubstr: "\\begin{bmatrix}\n0 & 1 \\\\\n2 & 3\n\\end{bmatrix}\n",
+		},
+		{
+			name:                   "markdown accepted",
+			endpoint:               "/invert",
+			filePath:               "testdata/valid_2_to_2.csv",
+			accept:                 "text/markdown",
+			expectedStatusCode:     200,
+			expectedContentType:    "text/markdown; charset=utf-8",
+			expectedResponseSubstr: "| 1 | 2 |\n| ---: | ---: |\n| 0 | 2 |\n| 1 | 3 |\n",
+		},
+		{
+			name:                   "html accepted by a browser",
+			endpoint:               "/echo",
+			filePath:               "testdata/valid_2_to_2.csv",
+			accept:                 "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8",
+			expectedStatusCode:     200,
+			expectedContentType:    "text/html; charset=utf-8",
+			expectedResponseSubstr: "<tr><td>0</td><td>1</td></tr>\n<tr><td>2</td><td>3</td></tr>\n",
+		},
+		{
+			name:                   "html refused with q=0",
+			endpoint:               "/echo",
+			filePath:               "testdata/valid_2_to_2.csv",
+			accept:                 "text/html;q=0",
+			expectedStatusCode:     200,
+			expectedResponseSubstr: "0,1\n2,3\n",
+		},
+		{
+			name:                   "highest quality wins",
+			endpoint:               "/echo",
+			filePath:               "testdata/valid_2_to_2.csv",
+			accept:                 "application/json;q=0.5, text/markdown",
+			expectedStatusCode:     200,
+			expectedContentType:    "text/markdown; charset=utf-8",
+			expectedResponseSubstr: "| 0 | 1 |\n",
+		},
+		{
+			name:                   "transposed labels in html",
+			endpoint:               "/invert?format=html&header=true&labels=true",
+			filePath:               "testdata/labeled.csv",
+			expectedStatusCode:     200,
+			expectedContentType:    "text/html; charset=utf-8",
+			expectedResponseSubstr: "<tr><th></th><th scope=\"col\">x</th><th scope=\"col\">y</th><th scope=\"col\">z</th></tr>",
+		},
+		{
+			name:                   "binary markdown",
+			endpoint:               "/echo?format=markdown&base=2",
+			filePath:               "testdata/valid_2_to_2.csv",
+			expectedStatusCode:     200,
+			expectedContentType:    "text/markdown; charset=utf-8",
+			expectedResponseSubstr: "| 0b0 | 0b1 |\n| 0b10 | 0b11 |\n",
+		},
+		{
+			name:                   "browser accept header falls back to csv for flatten",
+			endpoint:               "/flatten",
+			filePath:               "testdata/valid_2_to_2.csv",
+			accept:                 "text/html,*/*;q=0.8",
+			expectedStatusCode:     200,
+			expectedResponseSubstr: "0,1,2,3\n",
+		},
+		{
+			name:                   "latex format for a scalar",
+			endpoint:               "/sum?format=latex",
+			filePath:               "testdata/valid_2_to_2.csv",
+			expectedStatusCode:     400,
+			expectedResponseSubstr: "format \"latex\" is not supported for this result: use csv, table, npy or json",
+		},
+		{
+			name:                   "invalid format lists the renderers",
+			endpoint:               "/echo?format=xml",
+			filePath:               "testdata/valid_2_to_2.csv",
+			expectedStatusCode:     400,
+			expectedResponseSubstr: "invalid format \"xml\": use csv, table, mtx, mtx-array, mtx-coordinate, npy, json, latex, markdown or html",
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			req := createCSVRequest(s.T(), tc.endpoint, tc.filePath)
+			if tc.accept != "" {
+				req.Header.Set("Accept", tc.accept)
+			}
+			w := httptest.NewRecorder()
+			NewRouter(Config{}, discardLogger()).ServeHTTP(w, req)
+
+			resp := w.Result()
+			body, _ := io.ReadAll(resp.Body)
+
+			s.Equal(tc.expectedStatusCode, resp.StatusCode)
+			if tc.expectedContentType != "" {
+				s.Equal(tc.expectedContentType, resp.Header.Get("Content-Type"))
+			}
+			s.Contains(string(body), tc.expectedResponseSubstr)
+		})
+	}
+}
+
+// Test for acceptQuality
+func (s *RenderersTestSuite) TestAcceptQuality() {
+	s.Equal(1.0, acceptQuality("text/html", "text/html"))
+	s.Equal(0.5, acceptQuality("Text/HTML ; q=0.5, */*;q=0.1", "text/html"))
+	s.Equal(0.0, acceptQuality("text/html;q=0", "text/html"))
+	s.Equal(0.0, acceptQuality("*/*", "text/html"))
+	s.Equal(1.0, acceptQuality("text/html;level=1;q=invalid", "text/html"))
+}
+
+// Run all tests
+func TestRenderersTestSuite(t *testing.T) {
+	suite.Run(t, new(RenderersTestSuite))
+}
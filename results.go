@@ -0,0 +1,324 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"math"
+	"math/big"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strconv"
+	"strings"
+
+	"league_code_test/matrix"
+)
+
+// maxStepsSize caps the rows of the matrices whose rref steps are recorded, as every step
+// holds a copy of the matrix.
+const maxStepsSize = 32
+
+// propertiesJSON is the JSON output of the properties endpoint, converted from matrix.Properties.
+type propertiesJSON struct {
+	Trace           *big.Int `json:"trace"`
+	Rank            int      `json:"rank"`
+	Determinant     *big.Int `json:"determinant"`
+	Symmetric       bool     `json:"symmetric"`
+	SkewSymmetric   bool     `json:"skew_symmetric"`
+	Diagonal        bool     `json:"diagonal"`
+	UpperTriangular bool     `json:"upper_triangular"`
+	LowerTriangular bool     `json:"lower_triangular"`
+	Identity        bool     `json:"identity"`
+	Orthogonal      bool     `json:"orthogonal"`
+	Permutation     bool     `json:"permutation"`
+}
+
+// rrefJSON is the JSON output of the rref endpoint, with fractions such as "-1/2" as strings
+// and rows and columns numbered from 1.
+type rrefJSON struct {
+	Values       [][]*big.Rat       `json:"values"`
+	Rank         int                `json:"rank"`
+	PivotColumns []int              `json:"pivot_columns"`
+	ColLabels    []string           `json:"column_labels,omitempty"`
+	Steps        []rowOperationJSON `json:"steps,omitempty"`
+}
+
+// rowOperationJSON is a row operation of the rref steps.
+type rowOperationJSON struct {
+	Operation   string       `json:"operation"`
+	Description string       `json:"description"`
+	Row         int          `json:"row"`
+	Source      int          `json:"source,omitempty"`
+	Factor      *big.Rat     `json:"factor,omitempty"`
+	Values      [][]*big.Rat `json:"values"`
+}
+
+// writeRREF writes the reduced row echelon form of the matrix as JSON, or as csv rows of
+// fractions. With steps, the csv output starts with the matrix and follows with a block per row
+// operation, such as "R2 <- R2 - 4*R1" and the matrix after it, so the last block is the result.
+func writeRREF(w http.ResponseWriter, r *http.Request, format string, m *matrix.Matrix, e *matrix.RowEchelon, steps bool) {
+	if format == formatJSON {
+		result := rrefJSON{Values: e.Values, Rank: e.Rank(), PivotColumns: make([]int, len(e.Pivots)), ColLabels: m.ColLabels}
+		for i, col := range e.Pivots {
+			result.PivotColumns[i] = col + 1
+		}
+		for _, op := range e.Steps {
+			step := rowOperationJSON{Operation: op.Kind, Description: op.String(), Row: op.Row + 1, Factor: op.Factor, Values: op.Result}
+			if op.Kind != matrix.RowScale {
+				step.Source = op.Source + 1
+			}
+			result.Steps = append(result.Steps, step)
+		}
+		writeJSON(w, r, result)
+		return
+	}
+
+	bw := bufio.NewWriter(w)
+	writeRows := func(values [][]*big.Rat) {
+		for _, row := range values {
+			fields := make([]string, len(row))
+			for j, value := range row {
+				fields[j] = value.RatString()
+			}
+			bw.WriteString(strings.Join(fields, ",") + "\n")
+		}
+	}
+	if !steps {
+		writeRows(e.Values)
+		bw.Flush()
+		return
+	}
+
+	bw.WriteString("start\n")
+	for _, row := range m.Values {
+		bw.WriteString(formatRow(row, 10) + "\n")
+	}
+	for _, op := range e.Steps {
+		bw.WriteString("\n" + op.String() + "\n")
+		writeRows(op.Result)
+	}
+	bw.Flush()
+}
+
+// Output formats of decompositions.
+const (
+	formatMultipart = "multipart"
+)
+
+// readDecompositionInput reads the matrix of a decomposition and checks that the output format
+// is valid before the work starts. On failure it writes the error response and returns false.
+func readDecompositionInput(w http.ResponseWriter, r *http.Request) (*matrix.Matrix, bool) {
+	in, ok := readInput(w, r)
+	if !ok {
+		return nil, false
+	}
+	if _, err := factorsFormat(r); err != nil {
+		writeError(w, r, err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	m, err := in.eliminationMatrix()
+	if err != nil {
+		writeError(w, r, err.Error(), http.StatusRequestEntityTooLarge)
+		return nil, false
+	}
+	return m, true
+}
+
+// factorsFormat returns the output format of decompositions: json by default, or a
+// multipart/mixed response with a csv part per factor.
+func factorsFormat(r *http.Request) (string, error) {
+	switch format := r.URL.Query().Get("format"); format {
+	case "":
+		if accept := r.Header.Get("Accept"); acceptQuality(accept, "multipart/mixed") > acceptQuality(accept, "application/json") {
+			return formatMultipart, nil
+		}
+		return formatJSON, nil
+	case formatJSON, formatMultipart:
+		return format, nil
+	default:
+		return "", fmt.Errorf("invalid format %q: use json or multipart", format)
+	}
+}
+
+// writeFactors writes the named matrices of a decomposition as a JSON object such as
+// {"L": [[...]], "U": [[...]]}, or as a multipart/mixed response with a csv part per factor
+// named after it, such as L.csv.
+func writeFactors(w http.ResponseWriter, r *http.Request, factors []matrix.Factor) {
+	format, _ := factorsFormat(r)
+	if format == formatJSON {
+		result := make(map[string][][]float64, len(factors))
+		for _, f := range factors {
+			result[f.Name] = f.Values
+		}
+		writeJSON(w, r, result)
+		return
+	}
+
+	mw := multipart.NewWriter(w)
+	w.Header().Set("Content-Type", "multipart/mixed; boundary="+mw.Boundary())
+	for _, f := range factors {
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Type", "text/csv")
+		header.Set("Content-Disposition", fmt.Sprintf(`attachment; name=%q; filename="%s.csv"`, f.Name, f.Name))
+		part, err := mw.CreatePart(header)
+		if err != nil {
+			return
+		}
+		bw := bufio.NewWriter(part)
+		for _, row := range f.Values {
+			fields := make([]string, len(row))
+			for j, x := range row {
+				fields[j] = strconv.FormatFloat(x, 'g', -1, 64)
+			}
+			bw.WriteString(strings.Join(fields, ",") + "\n")
+		}
+		bw.Flush()
+	}
+	mw.Close()
+}
+
+// maxEigenIterations caps the max_iterations query parameter of the eigenvalues.
+const maxEigenIterations = 10000
+
+// eigenOptions returns the tolerance and the maximum number of QR steps of the eigenvalues of
+// a matrix of the size, set with the tolerance and max_iterations query parameters.
+func eigenOptions(r *http.Request, size int) (float64, int, error) {
+	query := r.URL.Query()
+	tolerance, maxIterations := matrix.DefaultEigenTolerance, 30*max(size, 1)
+	if value := query.Get("tolerance"); value != "" {
+		t, err := strconv.ParseFloat(value, 64)
+		if err != nil || !(t > 0 && t < 1) {
+			return 0, 0, fmt.Errorf("invalid tolerance %q: use a number between 0 and 1, such as 1e-12", value)
+		}
+		tolerance = t
+	}
+	if value := query.Get("max_iterations"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > maxEigenIterations {
+			return 0, 0, fmt.Errorf("invalid max_iterations %q: use an integer from 1 to %d", value, maxEigenIterations)
+		}
+		maxIterations = n
+	}
+	return tolerance, maxIterations, nil
+}
+
+// complexJSON is a complex number of the eigen endpoint, such as {"real": 1, "imag": -2}.
+type complexJSON struct {
+	Real float64 `json:"real"`
+	Imag float64 `json:"imag"`
+}
+
+func newComplexJSON(c complex128) complexJSON {
+	// Adding zero turns negative zeros into zeros
+	return complexJSON{Real: real(c) + 0, Imag: imag(c) + 0}
+}
+
+// eigenJSON is the JSON output of the eigen endpoint, with the eigenvector of every eigenvalue
+// at the same index.
+type eigenJSON struct {
+	Values         []complexJSON   `json:"eigenvalues"`
+	Vectors        [][]complexJSON `json:"eigenvectors"`
+	SpectralRadius float64         `json:"spectral_radius"`
+	Iterations     int             `json:"iterations"`
+}
+
+// writeEigen writes the eigenvalues and eigenvectors as JSON.
+func writeEigen(w http.ResponseWriter, r *http.Request, e *matrix.Eigen) {
+	result := eigenJSON{
+		Values:         make([]complexJSON, len(e.Values)),
+		Vectors:        make([][]complexJSON, len(e.Vectors)),
+		SpectralRadius: e.SpectralRadius(),
+		Iterations:     e.Iterations,
+	}
+	for i, value := range e.Values {
+		result.Values[i] = newComplexJSON(value)
+		result.Vectors[i] = make([]complexJSON, len(e.Vectors[i]))
+		for j, x := range e.Vectors[i] {
+			result.Vectors[i][j] = newComplexJSON(x)
+		}
+	}
+	writeJSON(w, r, result)
+}
+
+// maxPowerBits caps the bits of all the values of a matrix power without mod, estimated with
+// matrix.PowerBits before the work starts.
+const maxPowerBits = 1 << 24
+
+// powerExponent returns the exponent set with the n query parameter.
+func powerExponent(r *http.Request) (uint64, error) {
+	value := r.URL.Query().Get("n")
+	if value == "" {
+		return 0, fmt.Errorf("missing exponent: set n to a non-negative integer, such as n=2")
+	}
+	n, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid n %q: use an integer from 0 to %d", value, uint64(math.MaxUint64))
+	}
+	return n, nil
+}
+
+// checkPowerBits returns an error if the values of the matrix to the power n could have more
+// than maxPowerBits bits in total.
+func checkPowerBits(m *matrix.Matrix, n uint64) error {
+	size := m.Size()
+	limit := maxPowerBits / max(size*size, 1)
+	if bits := m.PowerBits(n); bits > float64(limit) {
+		return fmt.Errorf("values of the matrix to the power %d could have up to %.0f bits, the limit is %d bits for a %dx%d matrix: use mod to keep them bounded", n, math.Ceil(bits), limit, size, size)
+	}
+	return nil
+}
+
+// powerJSON is the JSON output of the power endpoint, with the labels of labeled csv input.
+type powerJSON struct {
+	Values    [][]*big.Int `json:"values"`
+	RowLabels []string     `json:"row_labels,omitempty"`
+	ColLabels []string     `json:"column_labels,omitempty"`
+}
+
+// writePower writes the power of the matrix as JSON, or as csv rows in the base with the labels
+// of the matrix as the header row and first column.
+func writePower(w http.ResponseWriter, r *http.Request, format string, base int, m *matrix.Matrix, values [][]*big.Int) {
+	if format == formatJSON {
+		writeJSON(w, r, powerJSON{Values: values, RowLabels: m.RowLabels, ColLabels: m.ColLabels})
+		return
+	}
+
+	cw := csv.NewWriter(w)
+	if m.ColLabels != nil {
+		header := m.ColLabels
+		if m.RowLabels != nil {
+			header = append([]string{""}, header...)
+		}
+		cw.Write(header)
+	}
+	for i, row := range values {
+		var fields []string
+		if m.RowLabels != nil {
+			fields = append(fields, m.RowLabels[i])
+		}
+		for _, x := range row {
+			fields = append(fields, withBasePrefix(x.Text(base), base))
+		}
+		cw.Write(fields)
+	}
+	cw.Flush()
+}
+
+// polynomialJSON is a polynomial of the charpoly endpoint, with its coefficients from the
+// leading one down to the constant term.
+type polynomialJSON struct {
+	Degree       int        `json:"degree"`
+	Coefficients []*big.Int `json:"coefficients"`
+	Polynomial   string     `json:"polynomial"`
+}
+
+func newPolynomialJSON(p matrix.Polynomial) *polynomialJSON {
+	return &polynomialJSON{Degree: p.Degree(), Coefficients: p, Polynomial: p.String()}
+}
+
+// polynomialsJSON is the JSON output of the charpoly endpoint.
+type polynomialsJSON struct {
+	Characteristic *polynomialJSON `json:"characteristic"`
+	Minimal        *polynomialJSON `json:"minimal,omitempty"`
+}
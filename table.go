@@ -0,0 +1,209 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"league_code_test/matrix"
+)
+
+// defaultTableMaxSize is how many rows and columns the table format shows before it elides
+// the middle ones.
+const defaultTableMaxSize = 20
+
+// tableEllipsis replaces the elided rows and columns of the table format.
+const tableEllipsis = "..."
+
+// tableOptions configure the table output format.
+type tableOptions struct {
+	// box draws the borders with box-drawing characters instead of padding with spaces
+	box bool
+	// indices numbers the rows and columns from 1, unless the matrix has labels
+	indices bool
+	// maxSize is the number of rows and columns shown, the middle ones are elided beyond it
+	maxSize int
+	base    int
+}
+
+// requestTableOptions returns the table options set by the query parameters of the request:
+//   - border: none (default) or box
+//   - indices: true to number the rows and columns, false by default
+//   - max_size: rows and columns shown before the middle ones are elided, 20 by default
+func requestTableOptions(r *http.Request, base int) (tableOptions, error) {
+	opts := tableOptions{maxSize: defaultTableMaxSize, base: base}
+	query := r.URL.Query()
+
+	switch border := query.Get("border"); border {
+	case "", "none":
+	case "box":
+		opts.box = true
+	default:
+		return opts, fmt.Errorf("invalid border %q: use none or box", border)
+	}
+	if value := query.Get("indices"); value != "" {
+		indices, err := strconv.ParseBool(value)
+		if err != nil {
+			return opts, fmt.Errorf("invalid indices %q: use true or false", value)
+		}
+		opts.indices = indices
+	}
+	if value := query.Get("max_size"); value != "" {
+		maxSize, err := strconv.Atoi(value)
+		if err != nil || maxSize < 2 {
+			return opts, fmt.Errorf("invalid max_size %q: use an integer of at least 2", value)
+		}
+		opts.maxSize = maxSize
+	}
+	return opts, nil
+}
+
+// shownIndexes returns the indexes of the n rows or columns shown in the table, with -1 in
+// place of the elided ones.
+func (opts tableOptions) shownIndexes(n int) []int {
+	var indexes []int
+	if n <= opts.maxSize {
+		for i := range n {
+			indexes = append(indexes, i)
+		}
+		return indexes
+	}
+
+	head := (opts.maxSize + 1) / 2
+	for i := range head {
+		indexes = append(indexes, i)
+	}
+	indexes = append(indexes, -1)
+	for i := n - (opts.maxSize - head); i < n; i++ {
+		indexes = append(indexes, i)
+	}
+	return indexes
+}
+
+// writeTable writes the rows of values as a table with right-aligned columns. Column labels
+// or indices are written as the header row, and row labels or indices as the first column.
+func writeTable(w io.Writer, values [][]int64, rowLabels []string, colLabels []string, opts tableOptions) {
+	cols := 0
+	if len(values) > 0 {
+		cols = len(values[0])
+	}
+	if opts.indices && rowLabels == nil {
+		rowLabels = indexLabels(len(values))
+	}
+	if opts.indices && colLabels == nil {
+		colLabels = indexLabels(cols)
+	}
+
+	// 1st Step: build the cells of the shown rows and columns
+	shownRows, shownCols := opts.shownIndexes(len(values)), opts.shownIndexes(cols)
+	var header []string
+	if colLabels != nil {
+		if rowLabels != nil {
+			header = append(header, "")
+		}
+		for _, j := range shownCols {
+			header = append(header, labelAt(colLabels, j))
+		}
+	}
+	var body [][]string
+	for _, i := range shownRows {
+		var row []string
+		if rowLabels != nil {
+			row = append(row, labelAt(rowLabels, i))
+		}
+		for _, j := range shownCols {
+			if i < 0 || j < 0 {
+				row = append(row, tableEllipsis)
+			} else {
+				row = append(row, formatInt(values[i][j], opts.base))
+			}
+		}
+		body = append(body, row)
+	}
+
+	// 2nd Step: measure the columns
+	widths := make([]int, len(body[0]))
+	for _, row := range append([][]string{header}, body...) {
+		for j, cell := range row {
+			widths[j] = max(widths[j], utf8.RuneCountInString(cell))
+		}
+	}
+
+	// 3rd Step: write the rows, row labels are left aligned and everything else right aligned
+	bw := bufio.NewWriter(w)
+	writeRow := func(cells []string) {
+		for j, cell := range cells {
+			pad := strings.Repeat(" ", widths[j]-utf8.RuneCountInString(cell))
+			if rowLabels != nil && j == 0 {
+				cell += pad
+			} else {
+				cell = pad + cell
+			}
+			switch {
+			case opts.box:
+				bw.WriteString("│ " + cell + " ")
+			case j > 0:
+				bw.WriteString("  " + cell)
+			default:
+				bw.WriteString(cell)
+			}
+		}
+		if opts.box {
+			bw.WriteString("│")
+		}
+		bw.WriteString("\n")
+	}
+	writeBorder := func(left, middle, right string) {
+		if !opts.box {
+			return
+		}
+		parts := make([]string, len(widths))
+		for j, width := range widths {
+			parts[j] = strings.Repeat("─", width+2)
+		}
+		bw.WriteString(left + strings.Join(parts, middle) + right + "\n")
+	}
+
+	writeBorder("┌", "┬", "┐")
+	if header != nil {
+		writeRow(header)
+		writeBorder("├", "┼", "┤")
+	}
+	for _, row := range body {
+		writeRow(row)
+	}
+	writeBorder("└", "┴", "┘")
+	bw.Flush()
+}
+
+// writeMatrixTable writes the matrix in the table format.
+func writeMatrixTable(w http.ResponseWriter, r *http.Request, m *matrix.Matrix, base int) {
+	opts, err := requestTableOptions(r, base)
+	if err != nil {
+		writeError(w, r, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	writeTable(w, m.Values, m.RowLabels, m.ColLabels, opts)
+}
+
+// indexLabels returns the labels "1" to "n".
+func indexLabels(n int) []string {
+	labels := make([]string, n)
+	for i := range labels {
+		labels[i] = strconv.Itoa(i + 1)
+	}
+	return labels
+}
+
+// labelAt returns the label at the index, or the ellipsis for an elided row or column.
+func labelAt(labels []string, i int) string {
+	if i < 0 {
+		return tableEllipsis
+	}
+	return labels[i]
+}
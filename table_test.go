@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type TableTestSuite struct {
+	suite.Suite
+}
+
+// Test for writeTable
+func (s *TableTestSuite) TestWriteTable() {
+	values := [][]int64{{1, 2, 3, 4}, {2, 2, -1, -10}, {3, 3, 5, -2}, {4, 3, 2, 1}}
+
+	tests := []struct {
+		name      string
+		values    [][]int64
+		rowLabels []string
+		colLabels []string
+		opts      tableOptions
+		expected  string
+	}{
+		{
+			name:     "plain columns are right aligned",
+			values:   values,
+			opts:     tableOptions{maxSize: 20, base: 10},
+			expected: "1  2   3    4\n2  2  -1  -10\n3  3   5   -2\n4  3   2    1\n",
+		},
+		{
+			name:   "box with indices",
+			values: [][]int64{{1, -20}, {300, 4}},
+			opts:   tableOptions{box: true, indices: true, maxSize: 20, base: 10},
+			expected: "┌───┬─────┬─────┐\n" +
+				"│   │   1 │   2 │\n" +
+				"├───┼─────┼─────┤\n" +
+				"│ 1 │   1 │ -20 │\n" +
+				"│ 2 │ 300 │   4 │\n" +
+				"└───┴─────┴─────┘\n",
+		},
+		{
+			name:      "labels are kept and row labels are left aligned",
+			values:    [][]int64{{1, 2}, {3, 4}},
+			rowLabels: []string{"x", "long"},
+			colLabels: []string{"a", "b"},
+			opts:      tableOptions{indices: true, maxSize: 20, base: 10},
+			expected:  "      a  b\nx     1  2\nlong  3  4\n",
+		},
+		{
+			name:     "middle rows and columns are elided",
+			values:   values,
+			opts:     tableOptions{indices: true, maxSize: 3, base: 10},
+			expected: "       1    2  ...    4\n1      1    2  ...    4\n2      2    2  ...  -10\n...  ...  ...  ...  ...\n4      4    3  ...    1\n",
+		},
+		{
+			name:     "hexadecimal values",
+			values:   [][]int64{{255, 16}, {1, 0}},
+			opts:     tableOptions{maxSize: 20, base: 16},
+			expected: "0xff  0x10\n 0x1   0x0\n",
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			var buf bytes.Buffer
+			writeTable(&buf, tc.values, tc.rowLabels, tc.colLabels, tc.opts)
+			s.Equal(tc.expected, buf.String())
+		})
+	}
+}
+
+// Test for the table format through the router
+func (s *TableTestSuite) TestTableFormat() {
+	tests := []struct {
+		name                   string
+		endpoint               string
+		expectedStatusCode     int
+		expectedResponseSubstr string
+	}{
+		{
+			name:                   "transposed table",
+			endpoint:               "/invert?format=table",
+			expectedStatusCode:     200,
+			expectedResponseSubstr: "1    2   3  4\n2    2   3  3\n3   -1   5  2\n4  -10  -2  1\n",
+		},
+		{
+			name:                   "flattened table",
+			endpoint:               "/flatten?format=table&max_size=4",
+			expectedStatusCode:     200,
+			expectedResponseSubstr: "1  2  ...  2  1\n",
+		},
+		{
+			name:                   "invalid border",
+			endpoint:               "/echo?format=table&border=double",
+			expectedStatusCode:     400,
+			expectedResponseSubstr: "invalid border \"double\": use none or box",
+		},
+		{
+			name:                   "invalid max_size",
+			endpoint:               "/echo?format=table&max_size=1",
+			expectedStatusCode:     400,
+			expectedResponseSubstr: "invalid max_size \"1\": use an integer of at least 2",
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			req := createCSVRequest(s.T(), tc.endpoint, "testdata/valid_4_to_4.csv")
+			w := httptest.NewRecorder()
+			NewRouter(Config{}, discardLogger()).ServeHTTP(w, req)
+
+			resp := w.Result()
+			body, _ := io.ReadAll(resp.Body)
+
+			s.Equal(tc.expectedStatusCode, resp.StatusCode)
+			s.Contains(string(body), tc.expectedResponseSubstr)
+		})
+	}
+}
+
+// Run all tests
+func TestTableTestSuite(t *testing.T) {
+	suite.Run(t, new(TableTestSuite))
+}
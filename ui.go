@@ -0,0 +1,40 @@
+package main
+
+import (
+	_ "embed"
+	"html/template"
+	"net/http"
+	"strings"
+)
+
+//go:embed static/index.html
+var indexHTML string
+
+var indexTemplate = template.Must(template.New("index").Parse(indexHTML))
+
+// IndexHandler serves the browser upload page, with one option per registered operation. The
+// page has no inputs for query parameters, so operations with a required one such as the
+// exponent of /power are left out.
+func IndexHandler(w http.ResponseWriter, r *http.Request) {
+	var operations []string
+	for _, rt := range routes {
+		if !hasRequiredParameter(rt) {
+			operations = append(operations, strings.TrimPrefix(rt.path, "/"))
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := indexTemplate.Execute(w, operations); err != nil {
+		writeError(w, r, "failed to render page", http.StatusInternalServerError)
+	}
+}
+
+// hasRequiredParameter reports whether the route has a query parameter that must be set.
+func hasRequiredParameter(rt route) bool {
+	for _, parameter := range rt.parameters {
+		if required, _ := parameter["required"].(bool); required {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type UITestSuite struct {
+	suite.Suite
+}
+
+// Test for IndexHandler through the router
+func (s *UITestSuite) TestIndexPage() {
+	keys, err := ParseAPIKeys("secret")
+	s.Require().NoError(err)
+	router := NewRouter(Config{APIKeys: keys}, discardLogger())
+
+	req := httptest.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	resp := w.Result()
+	body, _ := io.ReadAll(resp.Body)
+
+	// The page is public and offers every registered operation without required parameters
+	s.Equal(200, resp.StatusCode)
+	s.Equal("text/html; charset=utf-8", resp.Header.Get("Content-Type"))
+	for _, rt := range routes {
+		if rt.path == "/power" {
+			s.NotContains(string(body), `<option value="power">`)
+			continue
+		}
+		s.Contains(string(body), `<option value="`+rt.path[1:]+`">`)
+	}
+
+	// Unknown paths are not served by the page
+	req = httptest.NewRequest("GET", "/unknown", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	s.Equal(404, w.Code)
+}
+
+// Run all tests
+func TestUITestSuite(t *testing.T) {
+	suite.Run(t, new(UITestSuite))
+}
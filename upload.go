@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// MatrixQueryParameter is the query parameter with a small inline matrix, such as "1,2;3,4"
+// where rows are separated by semicolons.
+const MatrixQueryParameter = "matrix"
+
+// upload is the matrix sent with a request, with the file name and content type that select
+// its input format.
+type upload struct {
+	io.Reader
+	io.Closer
+	// name is the lower case file name without a .gz extension, empty for raw bodies
+	name        string
+	contentType string
+}
+
+// ext returns the extension of the uploaded file name, such as ".csv".
+func (u upload) ext() string {
+	return path.Ext(u.name)
+}
+
+// openUpload returns the matrix sent with the request, from the first of:
+//   - the matrix query parameter, with rows separated by semicolons
+//   - the "file" field of a multipart/form-data body
+//   - the raw request body, such as a text/csv body sent with curl --data-binary
+//
+// Gzip compressed files and bodies are decompressed.
+func openUpload(r *http.Request) (upload, error) {
+	if values, ok := r.URL.Query()[MatrixQueryParameter]; ok {
+		reader := strings.NewReader(strings.ReplaceAll(values[0], ";", "\n"))
+		return upload{Reader: reader, Closer: io.NopCloser(reader), contentType: "text/csv"}, nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "multipart/form-data" {
+		return openUploadedFile(r)
+	}
+
+	// A raw body is read as is, so an empty one is reported like a missing file
+	body := bufio.NewReader(r.Body)
+	if _, err := body.Peek(1); err != nil {
+		return upload{}, fmt.Errorf("failed to read file: request has no body, send the matrix as a multipart file, a raw body or the %s query parameter", MatrixQueryParameter)
+	}
+	reader, err := decompressUpload(body)
+	if err != nil {
+		return upload{}, err
+	}
+	return upload{Reader: reader, Closer: r.Body, contentType: mediaType}, nil
+}
+
+// openUploadedFile returns the file uploaded in the "file" form field, decompressed if it
+// was uploaded with gzip.
+func openUploadedFile(r *http.Request) (upload, error) {
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		return upload{}, fmt.Errorf("failed to read file: %v", err)
+	}
+	reader, err := decompressUpload(file)
+	if err != nil {
+		file.Close()
+		return upload{}, err
+	}
+	return upload{
+		Reader:      reader,
+		Closer:      file,
+		name:        strings.TrimSuffix(strings.ToLower(header.Filename), ".gz"),
+		contentType: header.Header.Get("Content-Type"),
+	}, nil
+}
+
+// LiteralSemicolonsMiddleware escapes the semicolons of the query string so that they are
+// kept in values such as matrix=1,2;3,4, instead of being dropped by url.ParseQuery with a
+// warning in the server log.
+func LiteralSemicolonsMiddleware(next http.Handler) http.Handler {
+	// AllowQuerySemicolons silences the warning, there are no semicolons left for it to split on
+	next = http.AllowQuerySemicolons(next)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.RawQuery, ";") {
+			r2 := new(http.Request)
+			*r2 = *r
+			r2.URL = new(url.URL)
+			*r2.URL = *r.URL
+			r2.URL.RawQuery = strings.ReplaceAll(r.URL.RawQuery, ";", "%3B")
+			r = r2
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"league_code_test/matrix"
+)
+
+type UploadTestSuite struct {
+	suite.Suite
+}
+
+// Test for raw request bodies and the matrix query parameter through the router
+func (s *UploadTestSuite) TestInputSources() {
+	npyBody := &bytes.Buffer{}
+	s.Require().NoError(matrix.WriteNPY(npyBody, []int{2, 2}, []int64{1, 2, 3, 4}))
+	// A tiny file declaring a huge shape
+	oversizedNPY := &bytes.Buffer{}
+	s.Require().NoError(matrix.WriteNPY(oversizedNPY, []int{200000, 200000}, []int64{1}))
+	gzipBody := &bytes.Buffer{}
+	gz := gzip.NewWriter(gzipBody)
+	gz.Write([]byte("1,2\n3,4\n"))
+	gz.Close()
+
+	tests := []struct {
+		name                   string
+		target                 string
+		contentType            string
+		body                   []byte
+		expectedStatusCode     int
+		expectedResponseSubstr string
+	}{
+		{
+			name:                   "raw text/csv body",
+			target:                 "/sum",
+			contentType:            "text/csv",
+			body:                   []byte("1,2,3\n4,5,6\n7,8,9\n"),
+			expectedStatusCode:     200,
+			expectedResponseSubstr: "45\n",
+		},
+		{
+			name:                   "raw body without content type",
+			target:                 "/invert",
+			body:                   []byte("1;2\n3;4\n"),
+			expectedStatusCode:     200,
+			expectedResponseSubstr: "1,3\n2,4\n",
+		},
+		{
+			name:                   "raw gzip file body",
+			target:                 "/echo",
+			contentType:            "application/gzip",
+			body:                   gzipBody.Bytes(),
+			expectedStatusCode:     200,
+			expectedResponseSubstr: "1,2\n3,4\n",
+		},
+		{
+			name:                   "raw matrix market body",
+			target:                 "/sum",
+			contentType:            "application/x-matrix-market",
+			body:                   []byte("%%MatrixMarket matrix coordinate integer general\n2 2 1\n2 1 7\n"),
+			expectedStatusCode:     200,
+			expectedResponseSubstr: "7\n",
+		},
+		{
+			name:                   "oversized matrix market array header",
+			target:                 "/echo",
+			contentType:            "application/x-matrix-market",
+			body:                   []byte("%%MatrixMarket matrix array integer general\n200000 200000\n1\n"),
+			expectedStatusCode:     400,
+			expectedResponseSubstr: "array of 200000x200000 values is larger than the limit of 4194304 values",
+		},
+		{
+			name:                   "raw npy body",
+			target:                 "/flatten",
+			contentType:            "application/x-npy",
+			body:                   npyBody.Bytes(),
+			expectedStatusCode:     200,
+			expectedResponseSubstr: "1,2,3,4\n",
+		},
+		{
+			name:                   "oversized npy header",
+			target:                 "/echo",
+			contentType:            "application/x-npy",
+			body:                   oversizedNPY.Bytes(),
+			expectedStatusCode:     400,
+			expectedResponseSubstr: "failed to parse npy: 200000x200000 matrix is larger than the limit of 4194304 values",
+		},
+		{
+			name:                   "raw body is validated",
+			target:                 "/sum",
+			contentType:            "text/csv",
+			body:                   []byte("1,2\n3\n"),
+			expectedStatusCode:     400,
+			expectedResponseSubstr: "matrix is not square: row 2 has 1 columns, expected 2",
+		},
+		{
+			name:                   "empty raw body",
+			target:                 "/sum",
+			contentType:            "text/csv",
+			expectedStatusCode:     400,
+			expectedResponseSubstr: "failed to read file: request has no body",
+		},
+		{
+			name:                   "matrix query parameter",
+			target:                 "/invert?matrix=1,2;3,4",
+			expectedStatusCode:     200,
+			expectedResponseSubstr: "1,3\n2,4\n",
+		},
+		{
+			name:                   "escaped matrix query parameter",
+			target:                 "/sum?matrix=1%2C2%3B3%2C4",
+			expectedStatusCode:     200,
+			expectedResponseSubstr: "10\n",
+		},
+		{
+			name:                   "matrix query parameter with other parameters",
+			target:                 "/echo?format=mtx&matrix=1,0;0,2",
+			expectedStatusCode:     200,
+			expectedResponseSubstr: "%%MatrixMarket matrix array integer general\n2 2\n1\n0\n0\n2\n",
+		},
+		{
+			name:                   "matrix query parameter is validated",
+			target:                 "/sum?matrix=1,2;3",
+			expectedStatusCode:     400,
+			expectedResponseSubstr: "matrix is not square: row 2 has 1 columns, expected 2",
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			req := httptest.NewRequest("POST", tc.target, bytes.NewReader(tc.body))
+			if tc.contentType != "" {
+				req.Header.Set("Content-Type", tc.contentType)
+			}
+			w := httptest.NewRecorder()
+			NewRouter(Config{}, discardLogger()).ServeHTTP(w, req)
+
+			resp := w.Result()
+			body, _ := io.ReadAll(resp.Body)
+
+			s.Equal(tc.expectedStatusCode, resp.StatusCode)
+			s.Contains(string(body), tc.expectedResponseSubstr)
+		})
+	}
+}
+
+// Test that ParseCSVFile reads raw bodies and the matrix query parameter
+func (s *UploadTestSuite) TestParseCSVFile() {
+	req := httptest.NewRequest("POST", "/echo", strings.NewReader("1,2\n3,4\n"))
+	req.Header.Set("Content-Type", "text/csv")
+	records, err := ParseCSVFile(req)
+	s.NoError(err)
+	s.Equal([][]string{{"1", "2"}, {"3", "4"}}, records)
+
+	req = httptest.NewRequest("POST", "/echo?matrix=5,6%3B7,8", nil)
+	records, err = ParseCSVFile(req)
+	s.NoError(err)
+	s.Equal([][]string{{"5", "6"}, {"7", "8"}}, records)
+}
+
+// Test that semicolons in the query reach the handler over a real connection
+func (s *UploadTestSuite) TestLiteralSemicolons() {
+	server := httptest.NewServer(NewRouter(Config{}, discardLogger()))
+	defer server.Close()
+
+	resp, err := http.Post(server.URL+"/sum?matrix=1,2;3,4", "", nil)
+	s.Require().NoError(err)
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+
+	s.Equal(200, resp.StatusCode)
+	s.Equal("10\n", string(body))
+}
+
+// Run all tests
+func TestUploadTestSuite(t *testing.T) {
+	suite.Run(t, new(UploadTestSuite))
+}
@@ -1,76 +1,163 @@
 package main
 
 import (
-	"encoding/csv"
 	"fmt"
+	"io"
+	"math"
+	"math/big"
 	"net/http"
 	"strconv"
+	"strings"
+
+	"league_code_test/matrix"
 )
 
-// ParseCSVFile reads csv file and returns file records.
+// ParseCSVFile reads the csv file, raw body or matrix query parameter of the request and
+// returns its records.
 func ParseCSVFile(r *http.Request) ([][]string, error) {
+	parser, err := requestParser(r)
+	if err != nil {
+		return nil, err
+	}
+
 	// Get csv file
-	file, _, err := r.FormFile("file")
+	file, err := openUpload(r)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read file: %v", err)
+		return nil, err
 	}
 	defer file.Close()
 
-	// Read all records from csv file
-	reader := csv.NewReader(file)
-	// Disable automatic field count checking to allow different row length and header cases
-	// since we check them in latter ValidateSquareMatrix() to return more specific error.
-    reader.FieldsPerRecord = -1
-    records, err := reader.ReadAll()
+	records, _, err := readCSVRecords(r, file, parser)
+	return records, err
+}
+
+// readCSVRecords reads all records from the csv file with the parser, and the normalizations
+// applied in lenient mode.
+func readCSVRecords(r *http.Request, file io.Reader, parser matrix.Parser) ([][]string, []matrix.Normalization, error) {
+	records, normalizations, err := parser.ReadCSVNormalized(file)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse csv: %v", err)
+		return nil, nil, err
 	}
 
-	// If csv file is empty
-	if len(records) == 0 {
-		return nil, fmt.Errorf("failed to parse csv: file is empty")
+	// Keep the matrix dimensions for the request log
+	recordMatrixSize(r, len(records), len(records[0]))
+
+	return records, normalizations, nil
+}
+
+// NormalizationsHeader lists the normalizations applied to lenient csv input, such as "bom, whitespace".
+const NormalizationsHeader = "X-Input-Normalizations"
+
+// requestParser returns the matrix parser configured by the query parameters of the request:
+//   - delimiter: auto (default), comma, tab, semicolon, pipe, whitespace or a single character
+//   - lenient: true to trim whitespace and skip BOMs, comments and blank lines, false by default
+//   - header: true to read the first row as column labels, false by default
+//   - labels: true to read the first column as row labels, false by default
+//   - prefixes: true to read integer literals such as 0xFF, 0b1010, 0o17 or 1_000, false by default
+func requestParser(r *http.Request) (matrix.Parser, error) {
+	parser := matrix.Parser{Delimiter: matrix.DelimiterAuto}
+
+	query := r.URL.Query()
+	if name := query.Get("delimiter"); name != "" {
+		delimiter, err := matrix.ParseDelimiter(name)
+		if err != nil {
+			return parser, err
+		}
+		parser.Delimiter = delimiter
+	}
+	options := []struct {
+		name   string
+		option *bool
+	}{
+		{"lenient", &parser.Lenient},
+		{"header", &parser.Header},
+		{"labels", &parser.Labels},
+		{"prefixes", &parser.BasePrefixes},
+	}
+	for _, opt := range options {
+		if value := query.Get(opt.name); value != "" {
+			enabled, err := strconv.ParseBool(value)
+			if err != nil {
+				return parser, fmt.Errorf("invalid %s %q: use true or false", opt.name, value)
+			}
+			*opt.option = enabled
+		}
 	}
 
-	return records, nil
+	return parser, nil
 }
 
-// ValidateSquareMatrix checks if the input matrix is square, contains only integers, and has no header row.
-func ValidateSquareMatrix(records [][]string) error {
-	// Empty matrix case
-	if len(records) == 0 {
-		return fmt.Errorf("empty matrix")
+// queryBool returns the boolean query parameter of the request, or the fallback when it is not set.
+func queryBool(r *http.Request, name string, fallback bool) (bool, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return fallback, nil
 	}
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s %q: use true or false", name, value)
+	}
+	return enabled, nil
+}
 
-	n := len(records[0])
-	// Check first row for header (all values should be integers)
-	for j, val := range records[0] {
-		if _, err := strconv.Atoi(val); err != nil {
-			return fmt.Errorf("matrix has a header row or non-integer value at row 1, column %d: %v", j+1, err)
-		}
+// queryModulus returns the modulus of modular arithmetic set with the mod query parameter, or
+// nil when it is not set.
+func queryModulus(r *http.Request) (*big.Int, error) {
+	value := r.URL.Query().Get("mod")
+	if value == "" {
+		return nil, nil
 	}
+	mod, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || mod < 2 {
+		return nil, fmt.Errorf("invalid mod %q: use an integer from 2 to %d", value, int64(math.MaxInt64))
+	}
+	return big.NewInt(mod), nil
+}
 
-	for i, row := range records {
-		// Check if each row length is equal to the first row length
-		if len(row) != n {
-			return fmt.Errorf("matrix is not square: row %d has %d columns, expected %d", i+1, len(row), n)
-		}
+// basePrefixes are the Go integer literal prefixes of the output bases.
+var basePrefixes = map[int]string{2: "0b", 8: "0o", 10: "", 16: "0x"}
 
-		for j, val := range row {
-			// Check for empty value
-			if val == "" {
-				return fmt.Errorf("matrix has empty value at row %d, column %d", i+1, j+1)
-			}
-			// Check for integer
-			if _, err := strconv.Atoi(val); err != nil {
-				return fmt.Errorf("matrix value at row %d, column %d is not an integer: %v", i+1, j+1, err)
+// outputBase returns the base of the integers in text output such as csv and table, 10 unless
+// set with the base query parameter to 2, 8 or 16.
+func outputBase(r *http.Request, format string) (int, error) {
+	value := r.URL.Query().Get("base")
+	if value == "" {
+		return 10, nil
+	}
+	base, err := strconv.Atoi(value)
+	if _, ok := basePrefixes[base]; err != nil || !ok {
+		return 0, fmt.Errorf("invalid base %q: use 2, 8, 10 or 16", value)
+	}
+	if rd, _ := findRenderer(format); base != 10 && !rd.bases {
+		var formats []string
+		for _, rd := range matrixRenderers {
+			if rd.bases {
+				formats = append(formats, rd.format)
 			}
 		}
+		return 0, fmt.Errorf("base %d is only supported for %s output", base, joinOr(formats))
 	}
+	return base, nil
+}
 
-	// Check if number of rows equals number of columns
-	if len(records) != n {
-		return fmt.Errorf("matrix is not square: %d rows and %d columns", len(records), n)
+// formatRow returns the values in the base separated by commas.
+func formatRow(values []int64, base int) string {
+	fields := make([]string, len(values))
+	for i, num := range values {
+		fields[i] = formatInt(num, base)
 	}
+	return strings.Join(fields, ",")
+}
 
-	return nil
-}
\ No newline at end of file
+// formatInt returns the integer in the base with its literal prefix, such as 0xff or -0b101.
+func formatInt(num int64, base int) string {
+	return withBasePrefix(strconv.FormatInt(num, base), base)
+}
+
+// withBasePrefix inserts the literal prefix of the base after the sign of the digits.
+func withBasePrefix(digits string, base int) string {
+	if strings.HasPrefix(digits, "-") {
+		return "-" + basePrefixes[base] + digits[1:]
+	}
+	return basePrefixes[base] + digits
+}
@@ -5,7 +5,6 @@ import (
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
-	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/suite"
@@ -95,124 +94,6 @@ func (s *UtilsTestSuite) TestParseCSVFile() {
 	}
 }
 
-// Test for ValidateSquareMatrix
-func (s *UtilsTestSuite) TestValidateSquareMatrix() {
-	tests := []struct {
-		name        string
-		matrix      [][]string
-		expectErr   bool
-		errorSubstr string
-	}{
-		{
-			name: "valid square 2*2 matrix",
-			matrix: [][]string{
-				{"1", "2"},
-				{"3", "4"},
-			},
-			expectErr: false,
-		},
-		{
-			name: "valid square 3*3 matrix",
-			matrix: [][]string{
-				{"1", "2", "3"},
-				{"4", "5", "6"},
-				{"7", "8", "9"},
-			},
-			expectErr: false,
-		},
-		{
-			name: "valid square 5*5 matrix",
-			matrix: [][]string{
-				{"1", "2", "3", "4", "5"},
-				{"6", "7", "8", "9", "10"},
-				{"11", "12", "13", "14", "15"},
-				{"16", "17", "18", "19", "20"},
-				{"21", "22", "23", "24", "25"},
-			},
-			expectErr: false,
-		},
-		{
-			name: "empty matrix",
-			matrix: [][]string{},
-			expectErr:   true,
-			errorSubstr: "empty matrix",
-		},
-		{
-			name: "matrix has header row",
-			matrix: [][]string{
-				{"This is header", "row content"},
-				{"1", "2"},
-			},
-			expectErr:   true,
-			errorSubstr: "header row",
-		},
-		{
-			name: "matrix has different row length",
-			matrix: [][]string{
-				{"1", "2", "3"},
-				{"4", "5"},
-			},
-			expectErr:   true,
-			errorSubstr: "matrix is not square",
-		},
-		{
-			name: "matrix has empty value",
-			matrix: [][]string{
-				{"1", "2", "3"},
-				{"4", "5", "6"},
-				{"7", "8", ""},
-			},
-			expectErr:   true,
-			errorSubstr: "matrix has empty value",
-		},
-		{
-			name: "matrix has non-integer value",
-			matrix: [][]string{
-				{"1", "2", "3"},
-				{"4", "A", "6"},
-				{"7", "8", "9"},
-			},
-			expectErr:   true,
-			errorSubstr: "is not an integer",
-		},
-		{
-			name: "matrix has more rows than columns",
-			matrix: [][]string{
-				{"1", "2"},
-				{"3", "4"},
-				{"5", "6"},
-			},
-			expectErr:   true,
-			errorSubstr: "matrix is not square",
-		},
-		{
-			name: "matrix has more columns than rows",
-			matrix: [][]string{
-				{"1", "2", "3"},
-				{"4", "5", "6"},
-			},
-			expectErr:   true,
-			errorSubstr: "matrix is not square",
-		},
-	}
-
-	for _, tc := range tests {
-		s.Run(tc.name, func() {
-			err := ValidateSquareMatrix(tc.matrix)
-
-			// Check if error contain errorSubstr
-			if tc.expectErr {
-				s.Error(err)
-				if tc.errorSubstr != "" {
-					s.Contains(strings.ToLower(err.Error()), strings.ToLower(tc.errorSubstr))
-				}
-			} else {
-				s.NoError(err)
-			}
-		})
-	}
-}
-
 // Run all tests
 func TestUtilsTestSuite(t *testing.T) {
 	suite.Run(t, new(UtilsTestSuite))